@@ -0,0 +1,176 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrReadOnly is returned when setting a variable of a read-only Accessor, like a Snapshot.
+var ErrReadOnly = errors.New("env: read-only")
+
+// Accessor is the storage of the variables, the process environment by default,
+// which the package-level functions read and write, see SetDefault.
+// Implementations must be safe for concurrent use.
+type Accessor interface {
+	LookupEnv(key string) (string, bool)
+	Setenv(key, value string) error
+	Unsetenv(key string) error
+	// Environ returns the variables as KEY=VALUE entries.
+	Environ() []string
+}
+
+// Process is the Accessor of the process environment.
+var Process Accessor = process{}
+
+type process struct{}
+
+func (process) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (process) Setenv(key, value string) error {
+	return os.Setenv(key, value)
+}
+
+func (process) Unsetenv(key string) error {
+	return os.Unsetenv(key)
+}
+
+func (process) Environ() []string {
+	return os.Environ()
+}
+
+var accessor atomic.Pointer[Accessor]
+
+func init() {
+	accessor.Store(&Process)
+}
+
+// Default returns the Accessor used by the package-level functions.
+func Default() Accessor {
+	return *accessor.Load()
+}
+
+// SetDefault atomically replaces the Accessor used by the package-level functions
+// and returns the previous one, e.g. in tests:
+//
+//	defer env.SetDefault(env.SetDefault(env.NewMap(map[string]string{"PORT": "8080"})))
+//
+// A nil Accessor restores Process.
+func SetDefault(a Accessor) Accessor {
+	if a == nil {
+		a = Process
+	}
+	return *accessor.Swap(&a)
+}
+
+// NewMap returns an in-memory Accessor holding a copy of values.
+func NewMap(values map[string]string) Accessor {
+	m := &mapAccessor{vars: make(map[string]string, len(values))}
+	for k, v := range values {
+		m.vars[k] = v
+	}
+	return m
+}
+
+type mapAccessor struct {
+	mu   sync.RWMutex
+	vars map[string]string
+}
+
+func (m *mapAccessor) LookupEnv(key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.vars[key]
+	return v, ok
+}
+
+func (m *mapAccessor) Setenv(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vars[key] = value
+	return nil
+}
+
+func (m *mapAccessor) Unsetenv(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.vars, key)
+	return nil
+}
+
+func (m *mapAccessor) Environ() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return environ(m.vars)
+}
+
+// environ returns the sorted KEY=VALUE entries of vars.
+func environ(vars map[string]string) []string {
+	out := make([]string, 0, len(vars))
+	for k, v := range vars {
+		out = append(out, k+"="+v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// KV is a variable name and value pair.
+type KV struct {
+	Key   string
+	Value string
+}
+
+func (kv KV) String() string {
+	return kv.Key + "=" + kv.Value
+}
+
+// Environ returns the variables of the Default Accessor sorted by name.
+// Only the first of duplicated names is kept, as when looking them up.
+func Environ() []KV {
+	var kvs []KV
+	seen := make(map[string]bool)
+	for _, e := range Default().Environ() {
+		k, v, ok := strings.Cut(e, "=")
+		// skip windows' per-drive working directory entries, e.g. "=C:=C:\"
+		if !ok || k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		kvs = append(kvs, KV{Key: k, Value: v})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs
+}
+
+// Keys returns the sorted names of the variables of the Default Accessor starting
+// with prefix, matched case-insensitively if CaseInsensitive is set.
+func Keys(prefix string) []string {
+	var keys []string
+	for _, kv := range Environ() {
+		if strings.HasPrefix(kv.Key, prefix) || (CaseInsensitive() && len(kv.Key) >= len(prefix) && strings.EqualFold(kv.Key[:len(prefix)], prefix)) {
+			keys = append(keys, kv.Key)
+		}
+	}
+	return keys
+}
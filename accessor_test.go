@@ -0,0 +1,102 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSetDefault(t *testing.T) {
+	m := NewMap(map[string]string{"TEST_ACCESSOR": "1"})
+	prev := SetDefault(m)
+	if Default() != m {
+		t.Fatal("Default() is not the new accessor")
+	}
+	if v := Get[int]("TEST_ACCESSOR"); v != 1 {
+		t.Errorf("Get() = %v, want 1", v)
+	}
+	if err := Set("TEST_ACCESSOR_SET", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := os.LookupEnv("TEST_ACCESSOR_SET"); ok {
+		t.Error("Set() modified the process environment")
+	}
+	if want := "TEST_ACCESSOR=1,TEST_ACCESSOR_SET=2"; join(m.Environ(), ",") != want {
+		t.Errorf("Environ() = %v, want %v", m.Environ(), want)
+	}
+	if err := Unset("TEST_ACCESSOR"); err != nil {
+		t.Fatal(err)
+	}
+	if v := GetDefault("TEST_ACCESSOR", 3); v != 3 {
+		t.Errorf("GetDefault() = %v, want 3", v)
+	}
+
+	SetDefault(Freeze())
+	if err := Set("TEST_ACCESSOR", 4); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Set() error = %v, want ErrReadOnly", err)
+	}
+	if v := Get[int]("TEST_ACCESSOR_SET"); v != 2 {
+		t.Errorf("Get() = %v from snapshot, want 2", v)
+	}
+
+	if SetDefault(prev) == nil || Default() != Process {
+		t.Error("SetDefault() did not restore the process accessor")
+	}
+	SetDefault(nil)
+	if Default() != Process {
+		t.Error("SetDefault(nil) did not restore the process accessor")
+	}
+}
+
+func TestSetDefaultConcurrent(t *testing.T) {
+	defer SetDefault(Default())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefault(NewMap(map[string]string{"TEST_ACCESSOR": "1"}))
+		}()
+		go func() {
+			defer wg.Done()
+			Get[int]("TEST_ACCESSOR")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEnviron(t *testing.T) {
+	defer SetDefault(SetDefault(NewMap(map[string]string{
+		"B_VAR":   "2",
+		"A_VAR":   "1=one",
+		"A_OTHER": "",
+		"a_lower": "3",
+	})))
+	if want := "A_OTHER=,A_VAR=1=one,B_VAR=2,a_lower=3"; join(Environ(), ",") != want {
+		t.Errorf("Environ() = %v, want %v", Environ(), want)
+	}
+	if want := "A_OTHER,A_VAR"; join(Keys("A_"), ",") != want {
+		t.Errorf("Keys() = %v, want %v", Keys("A_"), want)
+	}
+	prev := CaseInsensitive()
+	SetCaseInsensitive(true)
+	defer SetCaseInsensitive(prev)
+	if want := "A_OTHER,A_VAR,a_lower"; join(Keys("a_"), ",") != want {
+		t.Errorf("Keys() = %v, want %v", Keys("a_"), want)
+	}
+}
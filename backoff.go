@@ -0,0 +1,80 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"time"
+)
+
+// Backoff is a retry delays schedule, e.g. BACKOFF=100ms,1s,5s,30s.
+type Backoff []time.Duration
+
+// GetBackoff parses the comma separated durations of the variable name,
+// returning def if it is not set or empty. Units may differ between elements,
+// and bare numbers are milliseconds. The schedule must be valid, see Backoff.Validate.
+func GetBackoff(name string, def Backoff) (Backoff, error) {
+	d, err := GetSliceWith[time.Duration](name, def, WithSliceMode(SliceStrict))
+	if err != nil {
+		return nil, err
+	}
+	b := Backoff(d)
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("env: %s: %w", Key(name), err)
+	}
+	return b, nil
+}
+
+// Validate checks that the delays are positive and do not decrease.
+func (b Backoff) Validate() error {
+	for i, d := range b {
+		if d <= 0 {
+			return fmt.Errorf("delay %d (%v) must be positive", i, d)
+		}
+		if i > 0 && d < b[i-1] {
+			return fmt.Errorf("delay %d (%v) is lower than the previous one (%v)", i, d, b[i-1])
+		}
+	}
+	return nil
+}
+
+// Delay returns the delay before the given retry, starting at 0.
+// Retries past the end of the schedule use the last delay.
+func (b Backoff) Delay(retry int) time.Duration {
+	if len(b) == 0 {
+		return 0
+	}
+	if retry < 0 {
+		retry = 0
+	}
+	if retry >= len(b) {
+		return b[len(b)-1]
+	}
+	return b[retry]
+}
+
+// Total returns the sum of the delays, i.e. the time spent waiting when all the retries fail.
+func (b Backoff) Total() time.Duration {
+	var t time.Duration
+	for _, d := range b {
+		t += d
+	}
+	return t
+}
+
+// Attempts returns the number of attempts of the schedule, i.e. the first one and the retries.
+func (b Backoff) Attempts() int {
+	return len(b) + 1
+}
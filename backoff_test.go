@@ -0,0 +1,53 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetBackoff(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_BACKOFF":      "100ms, 1s,5s,30s",
+		"TEST_BACKOFF_DESC": "1s,100",
+		"TEST_BACKOFF_ZERO": "0s,1s",
+		"TEST_BACKOFF_BAD":  "1s,soon",
+	})
+	b, err := GetBackoff("TEST_BACKOFF", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Total() != 36100*time.Millisecond || b.Attempts() != 5 {
+		t.Errorf("GetBackoff() = %v, total %v", b, b.Total())
+	}
+	for retry, want := range map[int]time.Duration{-1: 100 * time.Millisecond, 0: 100 * time.Millisecond, 2: 5 * time.Second, 10: 30 * time.Second} {
+		if got := b.Delay(retry); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+	for _, k := range []string{"TEST_BACKOFF_DESC", "TEST_BACKOFF_ZERO", "TEST_BACKOFF_BAD"} {
+		if _, err := GetBackoff(k, nil); err == nil {
+			t.Errorf("GetBackoff(%s) expected error", k)
+		}
+	}
+	def := Backoff{time.Second}
+	if got, err := GetBackoff("TEST_BACKOFF_MISSING", def); err != nil || len(got) != 1 {
+		t.Errorf("GetBackoff() = %v, %v, want default", got, err)
+	}
+	if (Backoff{}).Delay(1) != 0 {
+		t.Error("empty Backoff Delay() != 0")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache is a Source caching the values looked up from another source, typically
+// a secret store, and refreshing them once their TTL expired.
+// Changes detected on refresh are notified to the watchers, e.g. to rotate
+// database credentials without restarting.
+type Cache struct {
+	src Source
+	ttl time.Duration
+
+	mu       sync.Mutex
+	ttls     map[string]time.Duration
+	entries  map[string]*cacheEntry
+	watchers map[string]map[int]func(Change)
+	nextID   int
+	now      func() time.Time
+}
+
+type cacheEntry struct {
+	value   string
+	ok      bool
+	expires time.Time
+}
+
+// NewCache returns a Cache over s whose values expire after ttl.
+// A zero ttl means the values never expire.
+func NewCache(s Source, ttl time.Duration) *Cache {
+	return &Cache{
+		src:      s,
+		ttl:      ttl,
+		ttls:     make(map[string]time.Duration),
+		entries:  make(map[string]*cacheEntry),
+		watchers: make(map[string]map[int]func(Change)),
+	}
+}
+
+func (c *Cache) String() string {
+	return "cache(" + sourceName(c.src) + ")"
+}
+
+func (c *Cache) time() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// TTL overrides the time to live of key.
+func (c *Cache) TTL(key string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttls[key] = ttl
+	if e, ok := c.entries[key]; ok {
+		e.expires = c.expiry(key)
+	}
+}
+
+func (c *Cache) expiry(key string) time.Time {
+	ttl, ok := c.ttls[key]
+	if !ok {
+		ttl = c.ttl
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return c.time().Add(ttl)
+}
+
+func (e *cacheEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// Lookup returns the cached value of key, looking it up from the underlying
+// source if it is not cached yet or expired.
+// If the lookup of an expired key fails, its stale value is returned and the error
+// is reported to the OnSourceError hook; the key is retried on the next lookup.
+func (c *Cache) Lookup(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	e, cached := c.entries[key]
+	var stale cacheEntry
+	if cached {
+		if !e.expired(c.time()) {
+			c.mu.Unlock()
+			return e.value, e.ok, nil
+		}
+		stale = *e
+	}
+	c.mu.Unlock()
+	v, ok, err := c.fetch(ctx, key)
+	// the caller gave up, do not hide it
+	if err != nil && cached && ctx.Err() == nil {
+		sourceError(key, err)
+		return stale.value, stale.ok, nil
+	}
+	return v, ok, err
+}
+
+// fetch looks up key from the underlying source, updating the cache and notifying
+// the watchers if the value changed.
+func (c *Cache) fetch(ctx context.Context, key string) (string, bool, error) {
+	name := sourceName(c.src)
+	start := time.Now()
+	v, ok, err := c.src.Lookup(ctx, key)
+	sourceLookup(name, time.Since(start))
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		Record(Provenance{Key: key, Origin: OriginSource, Source: name, Refreshed: c.time()}, v)
+	}
+	c.mu.Lock()
+	prev, cached := c.entries[key]
+	c.entries[key] = &cacheEntry{value: v, ok: ok, expires: c.expiry(key)}
+	var notify []func(Change)
+	if cached && (prev.value != v || prev.ok != ok) {
+		for _, fn := range c.watchers[key] {
+			notify = append(notify, fn)
+		}
+	}
+	c.mu.Unlock()
+	for _, fn := range notify {
+		fn(Change{Old: prev.value, New: v})
+	}
+	return v, ok, nil
+}
+
+// Refresh looks up again all the expired keys, returning the first error encountered.
+// Keys failing to refresh keep their stale value, are reported to the OnSourceError hook,
+// and are retried on the next refresh.
+func (c *Cache) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	now := c.time()
+	var keys []string
+	for k, e := range c.entries {
+		if e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	c.mu.Unlock()
+	var first error
+	for _, k := range keys {
+		_, _, err := c.fetch(ctx, k)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() == nil {
+			sourceError(k, err)
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if first == nil {
+		reloaded(c.time())
+	}
+	return first
+}
+
+// Run refreshes the expired keys every interval until ctx is done.
+func (c *Cache) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			_ = c.Refresh(ctx)
+		}
+	}
+}
+
+// Watch registers fn to be called when the value of key changes on refresh.
+// The returned function unregisters it.
+func (c *Cache) Watch(key string, fn func(Change)) (stop func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextID
+	c.nextID++
+	if c.watchers[key] == nil {
+		c.watchers[key] = make(map[int]func(Change))
+	}
+	c.watchers[key][id] = fn
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.watchers[key], id)
+	}
+}
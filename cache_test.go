@@ -0,0 +1,117 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	values := map[string]string{"DB_PASSWORD": "v1", "DB_USER": "app"}
+	calls := 0
+	src := SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		calls++
+		v, ok := values[key]
+		return v, ok, nil
+	})
+	c := NewCache(src, time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.TTL("DB_PASSWORD", time.Minute)
+
+	var changes []Change
+	stop := c.Watch("DB_PASSWORD", func(ch Change) {
+		changes = append(changes, ch)
+	})
+	for i := 0; i < 2; i++ {
+		if v, _, err := c.Lookup(ctx, "DB_PASSWORD"); err != nil || v != "v1" {
+			t.Fatalf("Lookup() = %q, %v", v, err)
+		}
+		if _, _, err := c.Lookup(ctx, "DB_USER"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("source calls = %d, want 2", calls)
+	}
+
+	values["DB_PASSWORD"] = "v2"
+	now = now.Add(time.Minute)
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("source calls = %d, want 3: only the expired key must be refreshed", calls)
+	}
+	if len(changes) != 1 || changes[0] != (Change{Old: "v1", New: "v2"}) {
+		t.Errorf("changes = %v", changes)
+	}
+	if v, _, _ := c.Lookup(ctx, "DB_PASSWORD"); v != "v2" {
+		t.Errorf("Lookup() = %q, want v2", v)
+	}
+
+	stop()
+	values["DB_PASSWORD"] = "v3"
+	now = now.Add(time.Minute)
+	if err := c.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("changes = %v after stop", changes)
+	}
+}
+
+func TestCacheStale(t *testing.T) {
+	ctx := context.Background()
+	fail := errors.New("unavailable")
+	var err error
+	c := NewCache(SourceFunc(func(context.Context, string) (string, bool, error) {
+		return "v1", true, err
+	}), time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	var reported []error
+	OnSourceError(func(key string, err error) {
+		reported = append(reported, err)
+	})
+	defer OnSourceError(nil)
+
+	if v, _, err := c.Lookup(ctx, "DB_PASSWORD"); err != nil || v != "v1" {
+		t.Fatalf("Lookup() = %q, %v", v, err)
+	}
+	err = fail
+	now = now.Add(time.Minute)
+	if v, ok, err := c.Lookup(ctx, "DB_PASSWORD"); err != nil || !ok || v != "v1" {
+		t.Errorf("Lookup() = %q, %v, %v, want stale value", v, ok, err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], fail) {
+		t.Errorf("reported errors = %v, want %v", reported, fail)
+	}
+	reported = nil
+	now = now.Add(time.Minute)
+	if err := c.Refresh(ctx); !errors.Is(err, fail) {
+		t.Errorf("Refresh() error = %v, want %v", err, fail)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], fail) {
+		t.Errorf("reported errors on refresh = %v, want %v", reported, fail)
+	}
+	if _, _, err := c.Lookup(ctx, "DB_USER"); !errors.Is(err, fail) {
+		t.Errorf("Lookup(uncached) error = %v, want %v", err, fail)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeOfDay is a wall clock time, parsed from 15:04 or 15:04:05, e.g. for maintenance windows.
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+// ParseTimeOfDay parses a 15:04 or 15:04:05 time of day.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return TimeOfDayOf(t), nil
+		}
+	}
+	return TimeOfDay{}, fmt.Errorf("invalid time of day %q: expected HH:MM or HH:MM:SS", s)
+}
+
+// TimeOfDayOf returns the time of day of t, in t's location.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second()}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *TimeOfDay) UnmarshalText(b []byte) error {
+	v, err := ParseTimeOfDay(string(b))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Duration returns the duration since midnight.
+func (t TimeOfDay) Duration() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute + time.Duration(t.Second)*time.Second
+}
+
+// Compare returns -1, 0 or 1 if t is before, equal to or after u.
+func (t TimeOfDay) Compare(u TimeOfDay) int {
+	return compare(t.Duration(), u.Duration())
+}
+
+// Before reports whether t is before u.
+func (t TimeOfDay) Before(u TimeOfDay) bool {
+	return t.Compare(u) < 0
+}
+
+// After reports whether t is after u.
+func (t TimeOfDay) After(u TimeOfDay) bool {
+	return t.Compare(u) > 0
+}
+
+// Between reports whether t is in the [start, end) window, which wraps around midnight
+// when end is before start, e.g. 23:00 to 02:00. The window is empty when start equals end.
+func (t TimeOfDay) Between(start, end TimeOfDay) bool {
+	switch c := start.Compare(end); {
+	case c == 0:
+		return false
+	case c < 0:
+		return !t.Before(start) && t.Before(end)
+	default:
+		return !t.Before(start) || t.Before(end)
+	}
+}
+
+// On returns the time t on the day of d, in d's location.
+func (t TimeOfDay) On(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour, t.Minute, t.Second, 0, d.Location())
+}
+
+func (t TimeOfDay) String() string {
+	if t.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// Date is a calendar date, parsed from 2006-01-02, e.g. for cutoff dates.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// ParseDate parses a 2006-01-02 date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", s)
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the date of t, in t's location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Date) UnmarshalText(b []byte) error {
+	v, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Compare returns -1, 0 or 1 if d is before, equal to or after e.
+func (d Date) Compare(e Date) int {
+	if c := compare(d.Year, e.Year); c != 0 {
+		return c
+	}
+	if c := compare(d.Month, e.Month); c != 0 {
+		return c
+	}
+	return compare(d.Day, e.Day)
+}
+
+// Before reports whether d is before e.
+func (d Date) Before(e Date) bool {
+	return d.Compare(e) < 0
+}
+
+// After reports whether d is after e.
+func (d Date) After(e Date) bool {
+	return d.Compare(e) > 0
+}
+
+// In returns the midnight starting the date d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d, or before if n is negative.
+func (d Date) AddDays(n int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, n))
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+func compare[T int | time.Month | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	setAll(t, map[string]string{"TEST_TIME_OF_DAY": "23:30", "TEST_TIME_OF_DAY_INVALID": "24:00"})
+	v := Get[TimeOfDay]("TEST_TIME_OF_DAY")
+	if v != (TimeOfDay{Hour: 23, Minute: 30}) || v.String() != "23:30" {
+		t.Errorf("Get() = %v, want 23:30", v)
+	}
+	if v := Get[TimeOfDay]("TEST_TIME_OF_DAY_INVALID"); v != (TimeOfDay{}) {
+		t.Errorf("Get() = %v, want zero value", v)
+	}
+	s, err := ParseTimeOfDay("02:15:30")
+	if err != nil || s.String() != "02:15:30" || s.Duration() != 2*time.Hour+15*time.Minute+30*time.Second {
+		t.Errorf("ParseTimeOfDay() = %v, %v", s, err)
+	}
+	if !s.Before(v) || !v.After(s) || v.Compare(v) != 0 {
+		t.Errorf("comparison of %v and %v", s, v)
+	}
+	window := func(start, end, at string) bool {
+		s, _ := ParseTimeOfDay(start)
+		e, _ := ParseTimeOfDay(end)
+		a, _ := ParseTimeOfDay(at)
+		return a.Between(s, e)
+	}
+	tests := []struct {
+		start, end, at string
+		want           bool
+	}{
+		{"09:00", "17:00", "12:00", true},
+		{"09:00", "17:00", "17:00", false},
+		{"23:00", "02:00", "23:30", true},
+		{"23:00", "02:00", "01:59", true},
+		{"23:00", "02:00", "12:00", false},
+		{"09:00", "09:00", "09:00", false},
+		{"09:00", "09:00", "12:00", false},
+	}
+	for _, tt := range tests {
+		if got := window(tt.start, tt.end, tt.at); got != tt.want {
+			t.Errorf("%s.Between(%s, %s) = %v, want %v", tt.at, tt.start, tt.end, got, tt.want)
+		}
+	}
+	day := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	if got := v.On(day); !got.Equal(time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("On() = %v", got)
+	}
+}
+
+func TestDate(t *testing.T) {
+	setAll(t, map[string]string{"TEST_DATE": "2024-06-01"})
+	d := Get[Date]("TEST_DATE")
+	if d != (Date{Year: 2024, Month: time.June, Day: 1}) || d.String() != "2024-06-01" {
+		t.Errorf("Get() = %v, want 2024-06-01", d)
+	}
+	if _, err := ParseDate("2024-02-30"); err == nil {
+		t.Error("ParseDate() expected error")
+	}
+	next := d.AddDays(30)
+	if next.String() != "2024-07-01" || !d.Before(next) || !next.After(d) || d.Compare(d) != 0 {
+		t.Errorf("AddDays() = %v", next)
+	}
+	if got := d.In(time.UTC); !got.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("In() = %v", got)
+	}
+	if DateOf(time.Date(2024, 6, 1, 23, 59, 0, 0, time.UTC)) != d {
+		t.Error("DateOf() mismatch")
+	}
+	var c struct {
+		Cutoff Date      `env:"TEST_DATE"`
+		Start  TimeOfDay `env:"TEST_TIME_OF_DAY_UNSET" default:"01:00"`
+	}
+	if err := Parse(&c); err != nil || c.Cutoff != d || c.Start.String() != "01:00" {
+		t.Errorf("Parse() = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPClient is the conventional set of variables configuring an HTTP client,
+// see HTTPClientConfig.
+type HTTPClient struct {
+	Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+	// Proxy is the proxy URL. When empty, the proxy environment variables are used.
+	Proxy              string        `env:"PROXY"`
+	InsecureSkipVerify bool          `env:"INSECURE_SKIP_VERIFY"`
+	MaxRetries         int           `env:"MAX_RETRIES"`
+	RetryBackoff       time.Duration `env:"RETRY_BACKOFF" default:"100ms"`
+}
+
+// HTTPClientConfig reads the HTTPClient configuration from the variables
+// <prefix>_TIMEOUT, <prefix>_PROXY, <prefix>_INSECURE_SKIP_VERIFY,
+// <prefix>_MAX_RETRIES and <prefix>_RETRY_BACKOFF.
+func HTTPClientConfig(prefix string) (HTTPClient, error) {
+	var c HTTPClient
+	if err := Parse(&c, WithPrefix(prefix)); err != nil {
+		return c, err
+	}
+	if c.Proxy != "" {
+		if _, err := url.Parse(c.Proxy); err != nil {
+			return c, &ParseError{Key: Key(prefixed(prefix, "PROXY")), Value: c.Proxy, Err: err}
+		}
+	}
+	return c, nil
+}
+
+// Transport returns a clone of http.DefaultTransport configured with the proxy
+// and TLS settings.
+func (c HTTPClient) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != "" {
+		if u, err := url.Parse(c.Proxy); err == nil {
+			t.Proxy = http.ProxyURL(u)
+		}
+	}
+	if c.InsecureSkipVerify {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.InsecureSkipVerify = true
+	}
+	return t
+}
+
+// Client returns an http.Client using the configured timeout and Transport.
+// Retries are left to the caller, e.g. using MaxRetries and RetryBackoff.
+func (c HTTPClient) Client() *http.Client {
+	return &http.Client{
+		Timeout:   c.Timeout,
+		Transport: c.Transport(),
+	}
+}
+
+// GRPCClient is the conventional set of variables configuring a gRPC client,
+// see GRPCClientConfig.
+type GRPCClient struct {
+	Target           string        `env:"ADDR,required"`
+	Timeout          time.Duration `env:"TIMEOUT" default:"30s"`
+	Insecure         bool          `env:"INSECURE"`
+	KeepaliveTime    time.Duration `env:"KEEPALIVE_TIME"`
+	KeepaliveTimeout time.Duration `env:"KEEPALIVE_TIMEOUT" default:"20s"`
+	MaxRetries       int           `env:"MAX_RETRIES"`
+}
+
+// GRPCClientConfig reads the GRPCClient configuration from the variables
+// <prefix>_ADDR, <prefix>_TIMEOUT, <prefix>_INSECURE, <prefix>_KEEPALIVE_TIME,
+// <prefix>_KEEPALIVE_TIMEOUT and <prefix>_MAX_RETRIES.
+func GRPCClientConfig(prefix string) (GRPCClient, error) {
+	var c GRPCClient
+	if err := Parse(&c, WithPrefix(prefix)); err != nil {
+		return c, err
+	}
+	return c, nil
+}
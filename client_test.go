@@ -0,0 +1,72 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientConfig(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_API_TIMEOUT":              "5s",
+		"TEST_API_PROXY":                "http://proxy:3128",
+		"TEST_API_INSECURE_SKIP_VERIFY": "true",
+		"TEST_API_MAX_RETRIES":          "3",
+	})
+	c, err := HTTPClientConfig("TEST_API")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HTTPClient{Timeout: 5 * time.Second, Proxy: "http://proxy:3128", InsecureSkipVerify: true, MaxRetries: 3, RetryBackoff: 100 * time.Millisecond}
+	if c != want {
+		t.Errorf("HTTPClientConfig() = %+v, want %+v", c, want)
+	}
+	hc := c.Client()
+	tr := hc.Transport.(*http.Transport)
+	if hc.Timeout != 5*time.Second || !tr.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("Client() = %+v", hc)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if u, err := tr.Proxy(req); err != nil || u.String() != "http://proxy:3128" {
+		t.Errorf("Client() proxy = %v, %v", u, err)
+	}
+}
+
+func TestHTTPClientConfigNoPrefix(t *testing.T) {
+	setAll(t, map[string]string{"PROXY": "http://[::1"})
+	_, err := HTTPClientConfig("")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "PROXY" {
+		t.Errorf("HTTPClientConfig() error = %v, want PROXY parse error", err)
+	}
+}
+
+func TestGRPCClientConfig(t *testing.T) {
+	if _, err := GRPCClientConfig("TEST_GRPC"); !errors.Is(err, ErrMissing) {
+		t.Errorf("GRPCClientConfig() error = %v, want ErrMissing", err)
+	}
+	setAll(t, map[string]string{"TEST_GRPC_ADDR": "dns:///svc:9090", "TEST_GRPC_KEEPALIVE_TIME": "1m"})
+	c, err := GRPCClientConfig("TEST_GRPC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := GRPCClient{Target: "dns:///svc:9090", Timeout: 30 * time.Second, KeepaliveTime: time.Minute, KeepaliveTimeout: 20 * time.Second}
+	if c != want {
+		t.Errorf("GRPCClientConfig() = %+v, want %+v", c, want)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeEnviron populates v, a pointer to the struct s was created from, like Parse,
+// but reads the Default Accessor Environ once and resolves all the variables from it instead of looking up
+// each of them, which is faster for schemas with many variables, especially when
+// matching names case-insensitively.
+func DecodeEnviron(s *Schema, v any, opts ...ParseOption) error {
+	var o parseOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Type() != s.typ {
+		return fmt.Errorf("env: DecodeEnviron expects a non-nil *%v, got %T", s.typ, v)
+	}
+	ci := CaseInsensitive()
+	exact := make(map[string]string)
+	var folded map[string]string
+	if ci {
+		folded = make(map[string]string)
+	}
+	for _, kv := range Environ() {
+		exact[kv.Key] = kv.Value
+		if _, dup := folded[strings.ToUpper(kv.Key)]; ci && !dup {
+			folded[strings.ToUpper(kv.Key)] = kv.Value
+		}
+	}
+	return s.decode(rv.Elem(), &o, func(f Var) (string, bool) {
+		key := Key(f.Name)
+		v, ok := exact[key]
+		if !ok && ci {
+			v, ok = folded[strings.ToUpper(key)]
+		}
+		looked(key, ok)
+		if ok {
+			v = resolve(key, v)
+		}
+		return v, ok
+	})
+}
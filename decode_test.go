@@ -0,0 +1,109 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeEnviron(t *testing.T) {
+	type config struct {
+		Addr  string   `env:"ADDR" default:":8080"`
+		Nodes []string `env:"NODES,required"`
+		HTTP  struct {
+			Port uint16 `env:"PORT"`
+		} `env:"TEST_DECODE_HTTP"`
+		Token string `env:"TEST_DECODE_TOKEN,required"`
+	}
+	setAll(t, map[string]string{
+		"NODES":                 "a,b",
+		"TEST_DECODE_HTTP_PORT": "8443",
+	})
+	s, err := NewSchema(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c config
+	err = DecodeEnviron(s, &c)
+	if !errors.Is(err, ErrMissing) {
+		t.Errorf("DecodeEnviron() error = %v, want ErrMissing", err)
+	}
+	if c.Addr != ":8080" || !reflect.DeepEqual(c.Nodes, []string{"a", "b"}) || c.HTTP.Port != 8443 {
+		t.Errorf("DecodeEnviron() = %+v", c)
+	}
+	setAll(t, map[string]string{"test_decode_token": "s3cr3t"})
+	prev := CaseInsensitive()
+	SetCaseInsensitive(true)
+	defer SetCaseInsensitive(prev)
+	c = config{}
+	if err := DecodeEnviron(s, &c); err != nil || c.Token != "s3cr3t" {
+		t.Errorf("DecodeEnviron() = %+v, %v, want case-insensitive match", c, err)
+	}
+	if err := DecodeEnviron(s, &struct{}{}); err == nil {
+		t.Error("DecodeEnviron() expected type mismatch error")
+	}
+}
+
+// benchSchema returns the schema of a struct of n variables, all set in the environment.
+func benchSchema(b *testing.B, n int) (*Schema, reflect.Type) {
+	fields := make([]reflect.StructField, n)
+	vars := make(map[string]string, n)
+	for i := range fields {
+		name := fmt.Sprintf("BENCH_DECODE_VAR_%d", i)
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Var%d", i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(fmt.Sprintf(`env:"%s"`, name)),
+		}
+		vars[name] = fmt.Sprint(i)
+	}
+	for k, v := range vars {
+		b.Setenv(k, v)
+	}
+	typ := reflect.StructOf(fields)
+	s, err := NewSchema(reflect.New(typ).Interface())
+	if err != nil {
+		b.Fatal(err)
+	}
+	return s, typ
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, ci := range []bool{false, true} {
+		b.Run(fmt.Sprintf("case-insensitive=%v", ci), func(b *testing.B) {
+			prev := CaseInsensitive()
+			SetCaseInsensitive(ci)
+			defer SetCaseInsensitive(prev)
+			s, typ := benchSchema(b, 300)
+			b.Run("Parse", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := Parse(reflect.New(typ).Interface()); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("DecodeEnviron", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := DecodeEnviron(s, reflect.New(typ).Interface()); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Difference is the set of variables added, removed or changed between two environments.
+type Difference struct {
+	Added   map[string]string
+	Removed map[string]string
+	Changed map[string]Change
+}
+
+// Change is the old and new values of a changed variable.
+type Change struct {
+	Old string
+	New string
+}
+
+// Diff returns the variables added, removed or changed from a to b,
+// e.g. the environments of two processes as returned by OfPID.
+func Diff(a, b map[string]string) Difference {
+	d := Difference{
+		Added:   make(map[string]string),
+		Removed: make(map[string]string),
+		Changed: make(map[string]Change),
+	}
+	for k, v := range a {
+		n, ok := b[k]
+		switch {
+		case !ok:
+			d.Removed[k] = v
+		case n != v:
+			d.Changed[k] = Change{Old: v, New: n}
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			d.Added[k] = v
+		}
+	}
+	return d
+}
+
+// Empty reports whether the environments are identical.
+func (d Difference) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Keys returns the sorted names of all the added, removed and changed variables.
+func (d Difference) Keys() []string {
+	keys := make([]string, 0, len(d.Added)+len(d.Removed)+len(d.Changed))
+	for k := range d.Added {
+		keys = append(keys, k)
+	}
+	for k := range d.Removed {
+		keys = append(keys, k)
+	}
+	for k := range d.Changed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String renders the difference one variable per line, sorted by name.
+// Added variables are prefixed with "+", removed ones with "-" and changed ones with "~",
+// e.g. "~ KEY=old -> new".
+func (d Difference) String() string {
+	var b strings.Builder
+	for _, k := range d.Keys() {
+		if v, ok := d.Added[k]; ok {
+			fmt.Fprintf(&b, "+ %s=%s\n", k, v)
+		} else if v, ok := d.Removed[k]; ok {
+			fmt.Fprintf(&b, "- %s=%s\n", k, v)
+		} else if c, ok := d.Changed[k]; ok {
+			fmt.Fprintf(&b, "~ %s=%s -> %s\n", k, c.Old, c.New)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := map[string]string{"A": "1", "B": "2", "C": "3"}
+	b := map[string]string{"A": "1", "B": "20", "D": "4"}
+	d := Diff(a, b)
+	if d.Empty() {
+		t.Fatal("Diff() is empty")
+	}
+	want := "~ B=2 -> 20\n- C=3\n+ D=4\n"
+	if got := d.String(); got != want {
+		t.Errorf("Diff().String() = %q, want %q", got, want)
+	}
+	if !Diff(a, a).Empty() {
+		t.Error("Diff(a, a) is not empty")
+	}
+	if got := Diff(nil, b).Keys(); join(got, ",") != "A,B,D" {
+		t.Errorf("Diff(nil, b).Keys() = %v", got)
+	}
+}
@@ -0,0 +1,484 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dotenv loads environment variables from .env files.
+//
+// Each line holds a KEY=value assignment, optionally prefixed with export.
+// Values may be unquoted, single quoted (literal) or double quoted, in which case
+// \n, \r, \t, \", \\ and \$ escapes are supported. Lines starting with # are comments,
+// as is the text following " #" in unquoted values.
+//
+// A single file can hold per-environment overlays, either as KEY@prod=value assignments
+// or as [prod] sections, applied over the base values when prod is the selected environment:
+//
+//	DB_HOST=localhost
+//	LOG_LEVEL@prod=info
+//
+//	[prod]
+//	DB_HOST=db.prod.internal
+//
+// Malformed lines are ignored and the last assignment of a key wins, unless the Strict
+// option is set, which reports them with their position, e.g. for CI validation.
+//
+// Save and Update modify files while preserving their comments and ordering.
+package dotenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.linka.cloud/env"
+)
+
+// entry is a variable assignment read from a file.
+type entry struct {
+	Key   string
+	Value string
+	// Env is the environment overlay the entry belongs to, empty for base entries.
+	Env  string
+	File string
+	Line int
+	// Comment is the trailing comment, including the leading #.
+	Comment string
+}
+
+// Option configures the parsing of the files.
+type Option func(o *options)
+
+type options struct {
+	env    string
+	envSet bool
+	strict bool
+	warn   func(err error)
+}
+
+// Strict rejects malformed lines and keys assigned more than once in the same file
+// and environment, instead of ignoring them or keeping the last value.
+// The issues are returned as *SyntaxError joined together.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// OnWarning registers fn to be called with the *SyntaxError ignored when not in strict mode.
+func OnWarning(fn func(err error)) Option {
+	return func(o *options) {
+		o.warn = fn
+	}
+}
+
+// Environment selects the environment overlay to apply. By default, the overlay
+// is selected by the APP_ENV or ENV variable, looked up in the process environment
+// and then in the files base values.
+func Environment(name string) Option {
+	return func(o *options) {
+		o.env = name
+		o.envSet = true
+	}
+}
+
+// EnvVars are the variables selecting the environment overlay, in order of precedence.
+var EnvVars = []string{"APP_ENV", "ENV"}
+
+// Loader reads dotenv files with options.
+type Loader struct {
+	o options
+}
+
+// New returns a Loader configured with opts.
+func New(opts ...Option) *Loader {
+	l := &Loader{}
+	for _, fn := range opts {
+		fn(&l.o)
+	}
+	return l
+}
+
+// Parse parses the dotenv formatted r, applying the selected environment overlay.
+func Parse(r io.Reader, opts ...Option) (map[string]string, error) {
+	return New(opts...).Parse(r)
+}
+
+// Read reads the files, values of later files overriding those of earlier ones,
+// and applies the selected environment overlay.
+func Read(paths ...string) (map[string]string, error) {
+	return New().Read(paths...)
+}
+
+// Load reads the files and sets the variables not already set in the process environment.
+// Without paths, it loads .env from the working directory.
+func Load(paths ...string) error {
+	return New().Load(paths...)
+}
+
+// Overload is like Load but overrides the variables already set.
+func Overload(paths ...string) error {
+	return New().Overload(paths...)
+}
+
+// Parse parses the dotenv formatted r, applying the selected environment overlay.
+func (l *Loader) Parse(r io.Reader) (map[string]string, error) {
+	entries, err := l.entries(func(p *parser) ([]entry, error) {
+		return p.parse(r, "", nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.resolve(entries), nil
+}
+
+// Read reads the files, values of later files overriding those of earlier ones,
+// and applies the selected environment overlay.
+func (l *Loader) Read(paths ...string) (map[string]string, error) {
+	entries, err := l.entries(func(p *parser) ([]entry, error) {
+		return p.readFiles(paths)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l.resolve(entries), nil
+}
+
+// entries runs parse and handles the syntax issues according to the options.
+func (l *Loader) entries(parse func(p *parser) ([]entry, error)) ([]entry, error) {
+	p := &parser{}
+	entries, err := parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("dotenv: %w", err)
+	}
+	p.duplicates(entries)
+	if l.o.strict && len(p.issues) != 0 {
+		return nil, errors.Join(p.issues...)
+	}
+	if l.o.warn != nil {
+		for _, err := range p.issues {
+			l.o.warn(err)
+		}
+	}
+	return entries, nil
+}
+
+// Load reads the files and sets the variables not already set in the process environment.
+// Without paths, it loads .env from the working directory.
+// The file and line of the values are recorded, see env.Explain.
+func (l *Loader) Load(paths ...string) error {
+	return l.load(paths, false)
+}
+
+// Overload is like Load but overrides the variables already set.
+func (l *Loader) Overload(paths ...string) error {
+	return l.load(paths, true)
+}
+
+func (l *Loader) load(paths []string, override bool) error {
+	if len(paths) == 0 {
+		paths = []string{".env"}
+	}
+	entries, err := l.entries(func(p *parser) ([]entry, error) {
+		return p.readFiles(paths)
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for k, e := range l.resolveEntries(entries) {
+		if _, ok := env.Default().LookupEnv(k); ok && !override {
+			continue
+		}
+		if err := env.Default().Setenv(k, e.Value); err != nil {
+			return err
+		}
+		env.Record(env.Provenance{Key: k, Origin: env.OriginFile, File: e.File, Line: e.Line, Refreshed: now}, e.Value)
+	}
+	return nil
+}
+
+// SyntaxError reports a malformed line or a key assigned more than once.
+type SyntaxError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", file, e.Line, e.Column, e.Msg)
+}
+
+// parser parses dotenv files, collecting the syntax issues.
+type parser struct {
+	issues []error
+}
+
+func (p *parser) issue(file string, line, col int, format string, args ...any) {
+	p.issues = append(p.issues, &SyntaxError{File: file, Line: line, Column: col, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) readFiles(paths []string) ([]entry, error) {
+	var entries []entry
+	for _, path := range paths {
+		e, err := p.parseFile(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e...)
+	}
+	return entries, nil
+}
+
+// parseFile parses the file at path. stack holds the absolute paths of the files
+// including it, to detect include cycles.
+func (p *parser) parseFile(path string, stack []string) ([]entry, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stack {
+		if s == abs {
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(append(stack, abs), " -> "))
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.parse(f, path, append(stack, abs))
+}
+
+// include returns the file included by line, if it is an include directive:
+// #include other.env or source other.env.
+func include(line string) (string, bool) {
+	for _, p := range []string{"#include ", "source "} {
+		if v, ok := strings.CutPrefix(line, p); ok {
+			v = strings.TrimSpace(v)
+			if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+				v = v[1 : len(v)-1]
+			}
+			return v, v != ""
+		}
+	}
+	return "", false
+}
+
+// resolve merges the base entries with the ones of the selected environment.
+func (l *Loader) resolve(entries []entry) map[string]string {
+	m := make(map[string]string)
+	for k, e := range l.resolveEntries(entries) {
+		m[k] = e.Value
+	}
+	return m
+}
+
+// resolveEntries is like resolve but returns the entries defining the values.
+func (l *Loader) resolveEntries(entries []entry) map[string]entry {
+	base := make(map[string]entry)
+	values := make(map[string]string)
+	for _, e := range entries {
+		if e.Env == "" {
+			base[e.Key] = e
+			values[e.Key] = e.Value
+		}
+	}
+	name := l.o.env
+	if !l.o.envSet {
+		name = selectEnv(values)
+	}
+	if name == "" {
+		return base
+	}
+	for _, e := range entries {
+		if e.Env == name {
+			base[e.Key] = e
+		}
+	}
+	return base
+}
+
+func selectEnv(base map[string]string) string {
+	for _, k := range EnvVars {
+		if v, ok := env.Default().LookupEnv(k); ok && v != "" {
+			return v
+		}
+	}
+	for _, k := range EnvVars {
+		if v := base[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parse parses r, read from file. Included files paths are resolved relative to file,
+// or to the working directory if empty.
+func (p *parser) parse(r io.Reader, file string, stack []string) ([]entry, error) {
+	var entries []entry
+	s := bufio.NewScanner(r)
+	section := ""
+	n := 0
+	for s.Scan() {
+		n++
+		raw := s.Text()
+		line := strings.TrimSpace(raw)
+		if inc, ok := include(line); ok {
+			if !filepath.IsAbs(inc) && file != "" {
+				inc = filepath.Join(filepath.Dir(file), inc)
+			}
+			e, err := p.parseFile(inc, stack)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+			}
+			// included base values belong to the including section
+			for i := range e {
+				if e[i].Env == "" {
+					e[i].Env = section
+				}
+			}
+			entries = append(entries, e...)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		start := n
+		e, col, msg := parseLine(raw, func() (string, bool) {
+			if !s.Scan() {
+				return "", false
+			}
+			n++
+			return s.Text(), true
+		})
+		if msg != "" {
+			p.issue(file, start, col, "%s", msg)
+			continue
+		}
+		if e.Env == "" {
+			e.Env = section
+		}
+		e.File, e.Line = file, start
+		entries = append(entries, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return entries, nil
+}
+
+// duplicates reports the keys assigned more than once in the same file and environment.
+func (p *parser) duplicates(entries []entry) {
+	type key struct {
+		file, env, key string
+	}
+	seen := make(map[key]entry)
+	for _, e := range entries {
+		k := key{file: e.File, env: e.Env, key: e.Key}
+		if prev, ok := seen[k]; ok {
+			p.issue(e.File, e.Line, 1, "duplicate key %s, previously assigned on line %d", e.Key, prev.Line)
+		}
+		seen[k] = e
+	}
+}
+
+// parseLine parses a KEY=value assignment, returning the 1-based column and
+// description of the error if the line is malformed.
+// next returns the following lines for multi-line quoted values.
+func parseLine(raw string, next func() (string, bool)) (e entry, col int, msg string) {
+	line := strings.TrimLeft(raw, " \t")
+	if rest, ok := strings.CutPrefix(line, "export "); ok {
+		line = strings.TrimLeft(rest, " \t")
+	}
+	off := len(raw) - len(line)
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return e, off + 1, "expected KEY=value"
+	}
+	e.Key, e.Env, _ = strings.Cut(strings.TrimSpace(line[:eq]), "@")
+	if e.Key == "" || strings.ContainsAny(e.Key, " \t") {
+		return e, off + 1, fmt.Sprintf("invalid key %q", strings.TrimSpace(line[:eq]))
+	}
+	v := line[eq+1:]
+	trimmed := strings.TrimLeft(v, " \t")
+	off += eq + 1 + len(v) - len(trimmed)
+	v = strings.TrimRight(trimmed, " \t")
+	if v == "" || (v[0] != '"' && v[0] != '\'') {
+		if i := strings.Index(v, " #"); i >= 0 {
+			v, e.Comment = strings.TrimSpace(v[:i]), strings.TrimSpace(v[i:])
+		}
+		e.Value = v
+		return e, 0, ""
+	}
+	val, rest, ok := unquote(v[1:], v[0], next)
+	if !ok {
+		return e, off + 1, "unterminated quoted value"
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return e, off + 1, fmt.Sprintf("unexpected %q after quoted value", rest)
+	}
+	e.Value, e.Comment = val, rest
+	return e, 0, ""
+}
+
+// unquote reads the quoted value up to the closing quote q, reading the next lines
+// if needed, and returns the text following it.
+// Escapes are only processed in double quoted values.
+func unquote(v string, q byte, next func() (string, bool)) (string, string, bool) {
+	var b strings.Builder
+	for {
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			switch {
+			case c == q:
+				return b.String(), v[i+1:], true
+			case c == '\\' && q == '"' && i+1 < len(v):
+				i++
+				switch v[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\', '$':
+					b.WriteByte(v[i])
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(v[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		l, ok := next()
+		if !ok {
+			return "", "", false
+		}
+		b.WriteByte('\n')
+		v = l
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"go.linka.cloud/env"
+)
+
+func check(t *testing.T, got, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func base() map[string]string {
+	return map[string]string{
+		"DB_HOST":   "localhost",
+		"DB_PORT":   "5432",
+		"GREETING":  "hello\n\"world\"",
+		"LITERAL":   `no $expansion \n here`,
+		"MULTI":     "line 1\nline 2",
+		"LOG_LEVEL": "debug",
+	}
+}
+
+func TestRead(t *testing.T) {
+	for _, k := range EnvVars {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	got, err := Read("testdata/overlay.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, base())
+}
+
+func TestOverlay(t *testing.T) {
+	prod := base()
+	prod["DB_HOST"] = "db.prod.internal"
+	prod["LOG_LEVEL"] = "info"
+	got, err := New(Environment("prod")).Read("testdata/overlay.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, prod)
+
+	t.Setenv("APP_ENV", "staging")
+	staging := base()
+	staging["DB_HOST"] = "db.staging.internal"
+	got, err = Read("testdata/overlay.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, staging)
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("APP_ENV", "")
+	// restore the environment once done
+	for k := range base() {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	os.Setenv("DB_HOST", "preset")
+	if err := Load("testdata/overlay.env"); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("DB_HOST"); got != "preset" {
+		t.Errorf("DB_HOST = %q, want preset", got)
+	}
+	if got := os.Getenv("DB_PORT"); got != "5432" {
+		t.Errorf("DB_PORT = %q, want 5432", got)
+	}
+	if p := env.Explain("DB_PORT"); p.Origin != env.OriginFile || p.File != "testdata/overlay.env" || p.Line != 3 {
+		t.Errorf("Explain(DB_PORT) = %v, want testdata/overlay.env:3", p)
+	}
+	if p := env.Explain("DB_HOST"); p.Origin != env.OriginEnv {
+		t.Errorf("Explain(DB_HOST) = %v, want env", p)
+	}
+	if err := Overload("testdata/overlay.env"); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("DB_HOST"); got != "localhost" {
+		t.Errorf("DB_HOST = %q, want localhost", got)
+	}
+	if err := Load("testdata/missing.env"); err == nil {
+		t.Error("Load() expected error")
+	}
+}
+
+func TestInclude(t *testing.T) {
+	got, err := New(Environment("")).Read("testdata/include.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, map[string]string{"DB_USER": "app", "DB_PORT": "5432", "APP": "web"})
+	got, err = New(Environment("prod")).Read("testdata/include.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, map[string]string{"DB_USER": "prod", "DB_PORT": "5432", "APP": "web", "DB_HOST": "db.prod.internal"})
+	_, err = Read("testdata/cycle-a.env")
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Read() error = %v, want include cycle", err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	want := []string{
+		"testdata/invalid.env:4:1: expected KEY=value",
+		`testdata/invalid.env:5:3: invalid key "BAD KEY"`,
+		`testdata/invalid.env:6:8: unexpected "trailing" after quoted value`,
+		"testdata/invalid.env:11:6: unterminated quoted value",
+		"testdata/invalid.env:3:1: duplicate key APP, previously assigned on line 2",
+		"testdata/invalid.env:10:1: duplicate key APP, previously assigned on line 7",
+	}
+	_, err := New(Environment(""), Strict()).Read("testdata/invalid.env")
+	if err == nil {
+		t.Fatal("Read() expected error")
+	}
+	if got := strings.Split(err.Error(), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Read() error =\n%v\nwant\n%s", err, strings.Join(want, "\n"))
+	}
+	var warnings []string
+	got, err := New(Environment(""), OnWarning(func(err error) {
+		warnings = append(warnings, err.Error())
+	})).Read("testdata/invalid.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, map[string]string{"APP": "api"})
+	if len(warnings) != len(want) {
+		t.Errorf("got %d warnings, want %d", len(warnings), len(want))
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.linka.cloud/env"
+)
+
+// Save writes values to the dotenv file at path, creating it if needed.
+// The comments, ordering and environment overlays of an existing file are preserved:
+// base assignments are updated in place, those of keys missing from values are removed,
+// and new keys are added in sorted order after the last base assignment.
+func Save(path string, values map[string]string) error {
+	return edit(path, values, true)
+}
+
+// Update sets key to value in the dotenv file at path, creating it if needed.
+// The rest of the file is left untouched.
+func Update(path, key, value string) error {
+	return edit(path, map[string]string{key: value}, false)
+}
+
+// edit rewrites the base assignments of the file at path with values,
+// removing the other base assignments if prune is set.
+func edit(path string, values map[string]string, prune bool) error {
+	for k := range values {
+		if k == "" || strings.ContainsAny(k, " \t\n=@#") {
+			return fmt.Errorf("dotenv: invalid key %q", k)
+		}
+	}
+	mode := fs.FileMode(0o644)
+	b, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	var lines []string
+	if len(b) != 0 {
+		lines = strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	}
+	var out []string
+	done := make(map[string]bool, len(values))
+	// last is the position following the last base assignment,
+	// header the position of the first section header
+	last, header := -1, -1
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		line := strings.TrimSpace(raw)
+		if _, ok := include(line); ok || header >= 0 || line == "" || strings.HasPrefix(line, "#") {
+			out = append(out, raw)
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			header = len(out)
+			out = append(out, raw)
+			continue
+		}
+		start := i
+		e, _, msg := parseLine(raw, func() (string, bool) {
+			if i+1 >= len(lines) {
+				return "", false
+			}
+			i++
+			return lines[i], true
+		})
+		if msg != "" || e.Env != "" {
+			out = append(out, lines[start:i+1]...)
+			continue
+		}
+		v, ok := values[e.Key]
+		switch {
+		case !ok && prune:
+			continue
+		case !ok:
+			out = append(out, lines[start:i+1]...)
+		default:
+			l := raw[:strings.IndexByte(raw, '=')+1] + env.QuoteDotenv(v)
+			if e.Comment != "" {
+				l += " " + e.Comment
+			}
+			out = append(out, l)
+			done[e.Key] = true
+		}
+		last = len(out)
+	}
+	var add []string
+	for k, v := range values {
+		if !done[k] {
+			add = append(add, k+"="+env.QuoteDotenv(v))
+		}
+	}
+	sort.Strings(add)
+	at := len(out)
+	switch {
+	case last >= 0:
+		at = last
+	case header >= 0:
+		at = header
+		add = append(add, "")
+	}
+	out = append(out[:at], append(add, out[at:]...)...)
+	var s string
+	if len(out) != 0 {
+		s = strings.Join(out, "\n") + "\n"
+	}
+	return writeFile(path, []byte(s), mode)
+}
+
+// writeFile atomically replaces the file at path with b.
+func writeFile(path string, b []byte, mode fs.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("dotenv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const saved = `# database
+export DB_HOST=localhost # local
+DB_PASS='secret'
+MULTI="line 1
+line 2"
+DB_HOST@prod=db.prod.internal
+
+[prod]
+LOG_LEVEL=info
+`
+
+func TestSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(saved), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, map[string]string{"DB_HOST": "db", "MULTI": "one\ntwo", "TOKEN": "a b", "API": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `# database
+export DB_HOST=db # local
+MULTI="one\ntwo"
+API=x
+TOKEN="a b"
+DB_HOST@prod=db.prod.internal
+
+[prod]
+LOG_LEVEL=info
+`
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, %v, want 0600", fi.Mode().Perm(), err)
+	}
+	got, err := New(Environment("")).Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, map[string]string{"DB_HOST": "db", "MULTI": "one\ntwo", "TOKEN": "a b", "API": "x"})
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := Update(path, "A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(saved), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(path, "DB_PASS", `p@ss"word`); err != nil {
+		t.Fatal(err)
+	}
+	got, err := New(Environment("prod")).Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(t, got, map[string]string{"DB_HOST": "db.prod.internal", "DB_PASS": `p@ss"word`, "MULTI": "line 1\nline 2", "LOG_LEVEL": "info"})
+	if err := Update(path, "BAD KEY", ""); err == nil {
+		t.Error("Update() expected error")
+	}
+
+	path = filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# only comments\n[prod]\nA=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Update(path, "B", "2"); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	if want := "# only comments\nB=2\n\n[prod]\nA=1\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
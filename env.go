@@ -15,185 +15,358 @@
 package env
 
 import (
+	"encoding"
 	"fmt"
 	"net"
 	"net/netip"
-	"os"
+	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+var caseInsensitive atomic.Bool
+
+func init() {
+	caseInsensitive.Store(runtime.GOOS == "windows")
+}
+
+// SetCaseInsensitive controls whether variable names are matched case-insensitively.
+// It defaults to true on windows, where environment variable names are case-insensitive.
+func SetCaseInsensitive(v bool) {
+	caseInsensitive.Store(v)
+}
+
+// CaseInsensitive reports whether variable names are matched case-insensitively.
+func CaseInsensitive() bool {
+	return caseInsensitive.Load()
+}
+
+// Value is the set of the supported types.
+// time.Duration is parsed either as a duration string or as milliseconds.
+// Named types based on a basic type, like type Port uint16, are parsed as their
+// underlying type: named duration types, like type Timeout time.Duration, are therefore
+// parsed as integer nanoseconds unless they implement encoding.TextUnmarshaler.
 type Value interface {
-	float32 | float64 |
-		uint | uint8 | uint16 | uint32 | uint64 |
-		int | int8 | int16 | int32 | int64 |
-		bool |
-		string |
-		time.Time | time.Duration |
+	~float32 | ~float64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~bool |
+		~string |
+		time.Time | Date | TimeOfDay |
+		Rate |
 		net.IP | net.IPNet | netip.Addr | netip.Prefix | netip.AddrPort
 }
 
 func Set[T Value](name string, v T) error {
-	return os.Setenv(name, fmt.Sprintf("%v", v))
+	return Default().Setenv(Key(name), fmt.Sprintf("%v", v))
 }
 
+// SetSlice sets the variable name to the comma separated list of the values.
+// Values containing commas, double quotes or surrounding spaces are double quoted
+// so that they can be read back losslessly by the slice getters.
 func SetSlice[T Value](name string, v []T) error {
 	var s []string
 	for _, v := range v {
-		s = append(s, fmt.Sprintf("%v", v))
+		s = append(s, quoteElem(fmt.Sprintf("%v", v)))
 	}
-	return os.Setenv(name, strings.Join(s, ","))
+	return Default().Setenv(Key(name), strings.Join(s, ","))
 }
 
 func Unset(name string) error {
-	return os.Unsetenv(name)
+	return Default().Unsetenv(Key(name))
 }
 
 func GetSlice[T Value](name string) []T {
 	var v []T
-	for _, s := range strings.Split(os.Getenv(name), ",") {
+	key := Key(name)
+	e, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+	}
+	for i, s := range splitList(e, true) {
 		var t T
-		setValue(s, &t)
+		if !ok {
+			v = append(v, t)
+			continue
+		}
+		if err := parseRaw(s, &t); err != nil {
+			parseError(fmt.Sprintf("%s[%d]", key, i), s, err)
+		}
 		v = append(v, t)
 	}
 	return v
 }
 
+// GetSliceDefault parses the comma separated variable name, returning def if the variable
+// is not set or contains no elements.
+// Elements are parsed in SliceMerge mode: an invalid element keeps the default element
+// at the same index. Use GetSliceWith to select another SliceMode.
 func GetSliceDefault[T Value](name string, def []T) []T {
-	v, ok := os.LookupEnv(name)
-	if !ok {
-		return def
-	}
-	vs := strings.Split(v, ",")
-	var s []string
-	for _, v := range vs {
-		if v = strings.TrimSpace(v); v != "" {
-			s = append(s, v)
-		}
-	}
-	if len(s) == 0 {
-		return def
-	}
-	var out []T
-	for i, v := range s {
-		var val T
-		if i < len(def) {
-			val = def[i]
-		}
-		setValue(v, any(&val))
-		out = append(out, val)
-	}
-	return out
+	v, _ := GetSliceWith(name, def)
+	return v
 }
 
 func Get[T Value](name string) T {
 	var v T
-	setValue(os.Getenv(name), any(&v))
+	key := Key(name)
+	e, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return v
+	}
+	setValue(key, e, any(&v))
 	return v
 }
 
-func GetDefault[T Value](key string, defaultVal T) T {
-	value, ok := os.LookupEnv(key)
+func GetDefault[T Value](name string, defaultVal T) T {
+	key := Key(name)
+	value, ok := lookupOS(key)
 	if !ok {
+		missing(key)
 		return defaultVal
 	}
-	setValue(value, any(&defaultVal))
+	setValue(key, value, any(&defaultVal))
 	return defaultVal
 }
 
-func setValue(s string, v any) {
-	s = strings.TrimSpace(s)
+func lookup(name string) (string, bool) {
+	return lookupOS(Key(name))
+}
+
+// lookupOS looks up the already mapped variable name using the Default Accessor,
+// resolving its value, see resolve.
+func lookupOS(name string) (string, bool) {
+	v, ok := lookupRaw(name)
+	if ok {
+		v = resolve(name, v)
+	}
+	return v, ok
+}
+
+// lookupRaw looks up the already mapped variable name using the Default Accessor,
+// returning its value as is.
+func lookupRaw(name string) (string, bool) {
+	a := Default()
+	v, ok := a.LookupEnv(name)
+	if !ok && CaseInsensitive() {
+		v, ok = lookupFold(a.Environ(), name)
+	}
+	looked(name, ok)
+	return v, ok
+}
+
+// resolve applies the global transforms, then the sentinels, to the value of key.
+// All the values read from the process environment or from the context overrides
+// go through it before being parsed.
+func resolve(key, v string) string {
+	return resolveSentinel(key, transform(key, v))
+}
+
+// lookupFold returns the value of the first KEY=VALUE entry of environ
+// whose key matches name case-insensitively.
+func lookupFold(environ []string, name string) (string, bool) {
+	for _, kv := range environ {
+		// skip windows' per-drive working directory entries, e.g. "=C:=C:\"
+		if strings.HasPrefix(kv, "=") {
+			continue
+		}
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// setValue parses s into v, reporting failures to the OnParseError hook.
+func setValue(key, s string, v any) {
+	if err := parseValue(s, v); err != nil {
+		parseError(key, s, err)
+	}
+}
+
+// parseValue parses the trimmed s into v, leaving v untouched on error.
+func parseValue(s string, v any) error {
+	return parseRaw(strings.TrimSpace(s), v)
+}
+
+// parseRaw parses s into v, leaving v untouched on error.
+func parseRaw(s string, v any) error {
 	switch v.(type) {
 	case *float32:
-		if f, err := strconv.ParseFloat(s, 32); err == nil {
-			*v.(*float32) = float32(f)
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
 		}
+		*v.(*float32) = float32(f)
 	case *float64:
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
-			*v.(*float64) = f
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
 		}
+		*v.(*float64) = f
 	case *uint:
-		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
-			*v.(*uint) = uint(u)
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
 		}
+		*v.(*uint) = uint(u)
 	case *uint8:
-		if u, err := strconv.ParseUint(s, 10, 8); err == nil {
-			*v.(*uint8) = uint8(u)
+		u, err := strconv.ParseUint(s, 10, 8)
+		if err != nil {
+			return err
 		}
+		*v.(*uint8) = uint8(u)
 	case *uint16:
-		if u, err := strconv.ParseUint(s, 10, 16); err == nil {
-			*v.(*uint16) = uint16(u)
+		u, err := strconv.ParseUint(s, 10, 16)
+		if err != nil {
+			return err
 		}
+		*v.(*uint16) = uint16(u)
 	case *uint32:
-		if u, err := strconv.ParseUint(s, 10, 32); err == nil {
-			*v.(*uint32) = uint32(u)
+		u, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return err
 		}
+		*v.(*uint32) = uint32(u)
 	case *uint64:
-		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
-			*v.(*uint64) = u
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
 		}
+		*v.(*uint64) = u
 	case *int:
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			*v.(*int) = int(i)
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
 		}
+		*v.(*int) = int(i)
 	case *int8:
-		if i, err := strconv.ParseInt(s, 10, 8); err == nil {
-			*v.(*int8) = int8(i)
+		i, err := strconv.ParseInt(s, 10, 8)
+		if err != nil {
+			return err
 		}
+		*v.(*int8) = int8(i)
 	case *int16:
-		if i, err := strconv.ParseInt(s, 10, 16); err == nil {
-			*v.(*int16) = int16(i)
+		i, err := strconv.ParseInt(s, 10, 16)
+		if err != nil {
+			return err
 		}
+		*v.(*int16) = int16(i)
 	case *int32:
-		if i, err := strconv.ParseInt(s, 10, 32); err == nil {
-			*v.(*int32) = int32(i)
+		i, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return err
 		}
+		*v.(*int32) = int32(i)
 	case *int64:
-		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
-			*v.(*int64) = i
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
 		}
+		*v.(*int64) = i
 	case *bool:
 		switch strings.ToLower(s) {
 		case "true", "yes", "on", "1":
 			*v.(*bool) = true
 		case "false", "no", "off", "0":
 			*v.(*bool) = false
+		default:
+			return fmt.Errorf("invalid boolean %q", s)
 		}
 	case *string:
 		*v.(*string) = s
 	case *net.IP:
-		if ip := net.ParseIP(s); ip != nil {
-			*v.(*net.IP) = ip
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", s)
 		}
+		*v.(*net.IP) = ip
 	case *net.IPNet:
-		if ip, ipnet, err := net.ParseCIDR(s); err == nil {
-			ipnet.IP = ip
-			*v.(*net.IPNet) = *ipnet
+		ip, ipnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return err
 		}
+		ipnet.IP = ip
+		*v.(*net.IPNet) = *ipnet
 	case *netip.Addr:
-		if addr, err := netip.ParseAddr(s); err == nil {
-			*v.(*netip.Addr) = addr
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return err
 		}
+		*v.(*netip.Addr) = addr
 	case *netip.Prefix:
-		if prefix, err := netip.ParsePrefix(s); err == nil {
-			*v.(*netip.Prefix) = prefix
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return err
 		}
+		*v.(*netip.Prefix) = prefix
 	case *netip.AddrPort:
-		if addrPort, err := netip.ParseAddrPort(s); err == nil {
-			*v.(*netip.AddrPort) = addrPort
+		addrPort, err := netip.ParseAddrPort(s)
+		if err != nil {
+			return err
 		}
+		*v.(*netip.AddrPort) = addrPort
 	case *time.Time:
-		if t, err := time.Parse(time.RFC3339, s); err == nil {
-			*v.(*time.Time) = t
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
 		}
+		*v.(*time.Time) = t
 	case *time.Duration:
-		if d, err := time.ParseDuration(s); err == nil {
+		d, err := time.ParseDuration(s)
+		if err == nil {
 			*v.(*time.Duration) = d
 			break
 		}
-		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
-			*v.(*time.Duration) = time.Duration(n) * time.Millisecond
+		n, err2 := strconv.ParseInt(s, 10, 64)
+		if err2 != nil {
+			return err
 		}
+		*v.(*time.Duration) = time.Duration(n) * time.Millisecond
+	case encoding.TextUnmarshaler:
+		return v.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	default:
+		return parseKind(s, v)
+	}
+	return nil
+}
+
+// kinds maps the basic kinds to the type used to parse the named types based on them,
+// e.g. type Port uint16.
+var kinds = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// parseKind parses s into v, a pointer to a type whose underlying type is a basic kind.
+func parseKind(s string, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w %T", errUnsupportedType, v)
+	}
+	t, ok := kinds[rv.Elem().Kind()]
+	if !ok {
+		return fmt.Errorf("%w %T", errUnsupportedType, v)
+	}
+	p := reflect.New(t)
+	if err := parseRaw(s, p.Interface()); err != nil {
+		return err
 	}
+	rv.Elem().Set(p.Elem().Convert(rv.Elem().Type()))
+	return nil
 }
@@ -15,8 +15,10 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -186,3 +188,181 @@ func TestEnvDate(t *testing.T) {
 	}
 	run(t, tests)
 }
+
+func TestCaseInsensitive(t *testing.T) {
+	prev := CaseInsensitive()
+	defer SetCaseInsensitive(prev)
+	if err := Set("TEST_CASE", 42); err != nil {
+		t.Fatal(err)
+	}
+	defer Unset("TEST_CASE")
+	SetCaseInsensitive(false)
+	if got := GetDefault("test_case", 1); got != 1 && runtime.GOOS != "windows" {
+		t.Errorf("GetDefault() = %v, want %v", got, 1)
+	}
+	SetCaseInsensitive(true)
+	if got := GetDefault("test_case", 1); got != 42 {
+		t.Errorf("GetDefault() = %v, want %v", got, 42)
+	}
+	if got := GetSliceDefault("Test_Case", []int{1}); join(got, ",") != "42" {
+		t.Errorf("GetSliceDefault() = %v, want %v", got, []int{42})
+	}
+}
+
+func TestLookupFold(t *testing.T) {
+	environ := []string{"=C:=C:\\", "Path=/bin", "FOO=bar"}
+	if v, ok := lookupFold(environ, "PATH"); !ok || v != "/bin" {
+		t.Errorf("lookupFold() = %q, %v, want %q, true", v, ok, "/bin")
+	}
+	if _, ok := lookupFold(environ, "=c:"); ok {
+		t.Error("lookupFold() matched a drive entry")
+	}
+	if _, ok := lookupFold(environ, "BAZ"); ok {
+		t.Error("lookupFold() matched a missing key")
+	}
+}
+
+func TestGetSliceWith(t *testing.T) {
+	if err := Set("TEST", "1,x,3"); err != nil {
+		t.Fatal(err)
+	}
+	def := []int{7, 8, 9, 10}
+	got, err := GetSliceWith("TEST", def)
+	if err != nil || join(got, ",") != "1,8,3" {
+		t.Errorf("GetSliceWith(merge) = %v, %v, want %v", got, err, []int{1, 8, 3})
+	}
+	got, err = GetSliceWith("TEST", def, WithSliceMode(SliceZeroFill))
+	if err != nil || join(got, ",") != "1,0,3" {
+		t.Errorf("GetSliceWith(zero-fill) = %v, %v, want %v", got, err, []int{1, 0, 3})
+	}
+	_, err = GetSliceWith("TEST", def, WithSliceMode(SliceStrict))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "TEST[1]" || perr.Value != "x" {
+		t.Errorf("GetSliceWith(strict) error = %v, want TEST[1] parse error", err)
+	}
+	if err := Set("TEST", "1,,x"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = GetSliceWith("TEST", def)
+	if err != nil || join(got, ",") != "1,8" {
+		t.Errorf("GetSliceWith(empty element) = %v, %v, want %v", got, err, []int{1, 8})
+	}
+	if got := GetSliceDefault("TEST", def); join(got, ",") != "1,8" {
+		t.Errorf("GetSliceDefault(empty element) = %v, want %v", got, []int{1, 8})
+	}
+	_, err = GetSliceWith("TEST", def, WithSliceMode(SliceStrict))
+	if !errors.As(err, &perr) || perr.Key != "TEST[2]" {
+		t.Errorf("GetSliceWith(empty element) error = %v, want TEST[2] parse error", err)
+	}
+	if err := Unset("TEST"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = GetSliceWith("TEST", def, WithSliceMode(SliceStrict))
+	if err != nil || join(got, ",") != join(def, ",") {
+		t.Errorf("GetSliceWith(unset) = %v, %v, want %v", got, err, def)
+	}
+}
+
+func TestGetSliceWithConstraints(t *testing.T) {
+	if err := Unset("TEST"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSliceWith[string]("TEST", nil, WithMinLen(1)); err == nil {
+		t.Error("GetSliceWith(minlen) expected error")
+	}
+	if err := Set("TEST", "a,b,a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSliceWith[string]("TEST", nil, WithUnique()); err == nil {
+		t.Error("GetSliceWith(unique) expected error")
+	}
+	if _, err := GetSliceWith[string]("TEST", nil, WithMaxLen(2)); err == nil {
+		t.Error("GetSliceWith(maxlen) expected error")
+	}
+	if got, err := GetSliceWith[string]("TEST", nil, WithMinLen(1), WithMaxLen(3)); err != nil || len(got) != 3 {
+		t.Errorf("GetSliceWith() = %v, %v", got, err)
+	}
+}
+
+func TestGetSliceValidated(t *testing.T) {
+	if err := Set("TEST", "https://a, http://b"); err != nil {
+		t.Fatal(err)
+	}
+	https := func(v string) error {
+		if !strings.HasPrefix(v, "https://") {
+			return errors.New("not https")
+		}
+		return nil
+	}
+	_, err := GetSliceValidated("TEST", https)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "TEST[1]" || perr.Value != "http://b" {
+		t.Errorf("GetSliceValidated() error = %v, want TEST[1] error", err)
+	}
+	if err := Set("TEST", "https://a,https://b"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetSliceValidated("TEST", https); err != nil || len(got) != 2 {
+		t.Errorf("GetSliceValidated() = %v, %v", got, err)
+	}
+}
+
+func TestSliceQuoting(t *testing.T) {
+	values := []string{"a,b", ` padded `, `say "hi"`, `back\slash`, "", "plain"}
+	if err := SetSlice("TEST", values); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[string]("TEST"); got != `"a,b"," padded ","say \"hi\"",back\slash,"",plain` {
+		t.Errorf("SetSlice() = %s", got)
+	}
+	if got := GetSlice[string]("TEST"); join(got, "|") != join(values, "|") {
+		t.Errorf("GetSlice() = %q, want %q", got, values)
+	}
+	if got := GetSliceDefault[string]("TEST", nil); join(got, "|") != join(values, "|") {
+		t.Errorf("GetSliceDefault() = %q, want %q", got, values)
+	}
+	tests := map[string][]string{
+		`a, "b,c" ,d`:  {"a", "b,c", "d"},
+		`"unclosed, b`: {`"unclosed`, "b"},
+		`"a"b, c`:      {`"a"b`, "c"},
+		`a"b,c`:        {`a"b`, "c"},
+		`,"",`:         {""},
+	}
+	for in, want := range tests {
+		if got := splitSlice(in); join(got, "|") != join(want, "|") || len(got) != len(want) {
+			t.Errorf("splitSlice(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+type testLevel int8
+
+type testName string
+
+func TestEnvNamed(t *testing.T) {
+	run(t, []TestCase[testLevel]{
+		{"TEST", "3", 3, 1},
+		{"TEST", "-1", -1, 0},
+	})
+	run(t, []TestCase[testName]{
+		{"TEST", "eu-west-1", "eu-west-1", "us-east-1"},
+	})
+	runSlice(t, []TestSliceCase[testName]{
+		{"TEST", "a,b", []testName{"a", "b"}, []testName{"c"}},
+	})
+}
+
+type testTimeout time.Duration
+
+func TestEnvNamedDuration(t *testing.T) {
+	setAll(t, map[string]string{"TEST_NAMED_DURATION": "5000"})
+	if v := Get[testTimeout]("TEST_NAMED_DURATION"); v != 5000 {
+		t.Errorf("Get() = %v, want 5000ns", time.Duration(v))
+	}
+	if err := Set("TEST_NAMED_DURATION", "5s"); err != nil {
+		t.Fatal(err)
+	}
+	if v := Get[testTimeout]("TEST_NAMED_DURATION"); v != 0 {
+		t.Errorf("Get() = %v, want 0: named durations are not parsed as duration strings", time.Duration(v))
+	}
+}
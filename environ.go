@@ -0,0 +1,38 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+)
+
+// ErrUnsupported is returned by OfPID on platforms without procfs.
+var ErrUnsupported = errors.New("env: not supported on " + runtime.GOOS)
+
+// parseEnviron parses a NUL separated list of KEY=VALUE entries,
+// as found in /proc/<pid>/environ.
+func parseEnviron(b []byte) map[string]string {
+	m := make(map[string]string)
+	for _, kv := range bytes.Split(b, []byte{0}) {
+		if len(kv) == 0 {
+			continue
+		}
+		k, v, _ := bytes.Cut(kv, []byte{'='})
+		m[string(k)] = string(v)
+	}
+	return m
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package env
+
+import (
+	"fmt"
+)
+
+// OfPID returns the environment of the process identified by pid.
+// It is only supported on linux and returns ErrUnsupported elsewhere.
+func OfPID(pid int) (map[string]string, error) {
+	return nil, fmt.Errorf("env: read environment of pid %d: %w", pid, ErrUnsupported)
+}
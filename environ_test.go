@@ -0,0 +1,55 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseEnviron(t *testing.T) {
+	got := parseEnviron([]byte("A=1\x00B=a=b\x00EMPTY=\x00\x00"))
+	want := map[string]string{"A": "1", "B": "a=b", "EMPTY": ""}
+	if len(got) != len(want) {
+		t.Fatalf("parseEnviron() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("parseEnviron()[%s] = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestOfPID(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		if _, err := OfPID(os.Getpid()); err == nil {
+			t.Fatal("OfPID() expected error")
+		}
+		return
+	}
+	// /proc/<pid>/environ reflects the initial environment only,
+	// so we only check that it can be read.
+	m, err := OfPID(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) == 0 {
+		t.Error("OfPID() returned an empty environment")
+	}
+	if _, err := OfPID(-1); err == nil {
+		t.Error("OfPID(-1) expected error")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissing is returned when a required variable is not set.
+var ErrMissing = errors.New("env: required variable not set")
+
+var errUnsupportedType = errors.New("unsupported type")
+
+// ParseError is returned when the value of an environment variable cannot be parsed.
+type ParseError struct {
+	// Key is the variable name, suffixed with the element index for slices, e.g. "HOSTS[2]".
+	Key string
+	// Value is the raw value that failed to parse.
+	Value string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("env: parse %s=%q: %v", e.Key, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates the errors of all the variables that failed to load.
+type Errors []error
+
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
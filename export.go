@@ -0,0 +1,188 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format is an Export output format.
+type Format int
+
+const (
+	// FormatDotenv outputs KEY=value lines, quoting values when needed.
+	FormatDotenv Format = iota
+	// FormatJSON outputs a JSON object.
+	FormatJSON
+	// FormatYAML outputs a YAML mapping.
+	FormatYAML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatDotenv:
+		return "dotenv"
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ExportOption configures Export.
+type ExportOption func(o *exportOptions)
+
+type exportOptions struct {
+	values map[string]string
+	schema *Schema
+	redact func(key string) bool
+}
+
+// FromValues exports the given variables, e.g. a snapshot, instead of the process environment.
+func FromValues(m map[string]string) ExportOption {
+	return func(o *exportOptions) {
+		o.values = m
+	}
+}
+
+// FromSchema exports the resolved values of the schema variables, in the schema order,
+// instead of the process environment. Secret variables are redacted.
+func FromSchema(s *Schema) ExportOption {
+	return func(o *exportOptions) {
+		o.schema = s
+	}
+}
+
+// WithRedact redacts the values of the variables for which fn returns true.
+func WithRedact(fn func(key string) bool) ExportOption {
+	return func(o *exportOptions) {
+		o.redact = fn
+	}
+}
+
+// Export writes the environment of the Default Accessor to w in the given format.
+func Export(w io.Writer, format Format, opts ...ExportOption) error {
+	var o exportOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	kvs := o.entries()
+	for i, kv := range kvs {
+		if o.redact != nil && o.redact(kv.key) {
+			kvs[i].value = redacted
+		}
+	}
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatDotenv:
+		for _, kv := range kvs {
+			fmt.Fprintf(bw, "%s=%s\n", kv.key, QuoteDotenv(kv.value))
+		}
+	case FormatJSON:
+		err = writeMapping(bw, kvs, "{\n", "  %s: %s%s\n", "}\n")
+	case FormatYAML:
+		err = writeMapping(bw, kvs, "", "%s: %s%s\n", "")
+	default:
+		return fmt.Errorf("env: unsupported export format %v", format)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+type exportEntry struct {
+	key   string
+	value string
+}
+
+func (o *exportOptions) entries() []exportEntry {
+	if o.schema != nil {
+		kvs := make([]exportEntry, 0, len(o.schema.Vars))
+		for _, v := range o.schema.Vars {
+			raw, origin := v.resolve()
+			if v.Secret && origin != originUnset {
+				raw = redacted
+			}
+			kvs = append(kvs, exportEntry{key: v.Name, value: raw})
+		}
+		return kvs
+	}
+	if o.values == nil {
+		env := Environ()
+		kvs := make([]exportEntry, len(env))
+		for i, kv := range env {
+			kvs[i] = exportEntry{key: kv.Key, value: kv.Value}
+		}
+		return kvs
+	}
+	kvs := make([]exportEntry, 0, len(o.values))
+	for k, v := range o.values {
+		kvs = append(kvs, exportEntry{key: k, value: v})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].key < kvs[j].key
+	})
+	return kvs
+}
+
+// writeMapping writes kvs as JSON strings, which are valid YAML double quoted scalars.
+func writeMapping(w io.Writer, kvs []exportEntry, open, line, close string) error {
+	io.WriteString(w, open)
+	for i, kv := range kvs {
+		k, err := json.Marshal(kv.key)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(kv.value)
+		if err != nil {
+			return err
+		}
+		sep := ""
+		if close != "" && i < len(kvs)-1 {
+			sep = ","
+		}
+		fmt.Fprintf(w, line, k, v, sep)
+	}
+	_, err := io.WriteString(w, close)
+	return err
+}
+
+// QuoteDotenv returns v as a dotenv value: unchanged if it only contains safe
+// characters, double quoted otherwise, with backslashes, double quotes, dollar signs,
+// newlines, carriage returns and tabs escaped.
+// It is used by Export and by the dotenv package to write files.
+func QuoteDotenv(v string) string {
+	safe := true
+	for _, r := range v {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@%+=", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(v) + `"`
+}
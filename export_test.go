@@ -0,0 +1,70 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	values := map[string]string{
+		"B_PLAIN":  "host:8080",
+		"A_QUOTED": "a b \"c\" $HOME\nd\te",
+		"C_TOKEN":  "s3cr3t",
+	}
+	redact := WithRedact(func(key string) bool {
+		return strings.HasSuffix(key, "_TOKEN")
+	})
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatDotenv, "A_QUOTED=\"a b \\\"c\\\" \\$HOME\\nd\\te\"\nB_PLAIN=host:8080\nC_TOKEN=\"******\"\n"},
+		{FormatJSON, "{\n  \"A_QUOTED\": \"a b \\\"c\\\" $HOME\\nd\\te\",\n  \"B_PLAIN\": \"host:8080\",\n  \"C_TOKEN\": \"******\"\n}\n"},
+		{FormatYAML, "\"A_QUOTED\": \"a b \\\"c\\\" $HOME\\nd\\te\"\n\"B_PLAIN\": \"host:8080\"\n\"C_TOKEN\": \"******\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, tt.format, FromValues(values), redact); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Export() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportSchema(t *testing.T) {
+	type config struct {
+		Addr     string `env:"TEST_EXPORT_ADDR" default:":8080"`
+		Password string `env:"TEST_EXPORT_PASSWORD,secret"`
+	}
+	setAll(t, map[string]string{"TEST_EXPORT_PASSWORD": "s3cr3t"})
+	s, err := NewSchema(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, FormatDotenv, FromSchema(s)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "TEST_EXPORT_ADDR=:8080\nTEST_EXPORT_PASSWORD=\"******\"\n"; buf.String() != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
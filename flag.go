@@ -0,0 +1,96 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// FeatureFlag is a boolean feature flag read from an environment variable,
+// either enabled for everyone with a boolean value, e.g. FEATURE_X=true,
+// or rolled out to a percentage of keys, e.g. FEATURE_X=25%.
+type FeatureFlag struct {
+	name string
+}
+
+// Flag returns the FeatureFlag read from the variable name.
+// The variable is read on each call, so that the flag can be changed at runtime.
+func Flag(name string) FeatureFlag {
+	return FeatureFlag{name: name}
+}
+
+// Name returns the variable name of the flag.
+func (f FeatureFlag) Name() string {
+	return f.name
+}
+
+// Rollout returns the percentage, between 0 and 100, of keys the flag is enabled for.
+// An unset or invalid value disables the flag.
+func (f FeatureFlag) Rollout() float64 {
+	key := Key(f.name)
+	raw, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return 0
+	}
+	p, err := parseRollout(raw)
+	if err != nil {
+		parseError(key, raw, err)
+		return 0
+	}
+	return p
+}
+
+// Enabled reports whether the flag is enabled for key, e.g. a user or tenant ID.
+// For percentage rollouts, keys are consistently bucketed using a hash of the flag
+// name and key, so that a key stays enabled while the percentage increases.
+func (f FeatureFlag) Enabled(key any) bool {
+	p := f.Rollout()
+	switch {
+	case p <= 0:
+		return false
+	case p >= 100:
+		return true
+	}
+	return f.bucket(key) < p
+}
+
+// bucket returns the position of key in [0, 100).
+func (f FeatureFlag) bucket(key any) float64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s:%v", f.name, key)
+	return float64(h.Sum64()%10000) / 100
+}
+
+func parseRollout(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "%") {
+		p, err := ParsePercent(s, PercentWhole)
+		if err != nil {
+			return 0, err
+		}
+		return float64(p) * 100, nil
+	}
+	var b bool
+	if err := parseRaw(s, &b); err != nil {
+		return 0, err
+	}
+	if b {
+		return 100, nil
+	}
+	return 0, nil
+}
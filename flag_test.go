@@ -0,0 +1,79 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+)
+
+func TestFlag(t *testing.T) {
+	f := Flag("TEST_FEATURE")
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"on", 100},
+		{"false", 0},
+		{"25%", 25},
+		{" 12.5 % ", 12.5},
+		{"150%", 0},
+		{"NaN%", 0},
+		{"maybe", 0},
+	}
+	for _, tt := range tests {
+		setAll(t, map[string]string{"TEST_FEATURE": tt.value})
+		if got := f.Rollout(); got != tt.want {
+			t.Errorf("%s: Rollout() = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+	if err := Unset("TEST_FEATURE"); err != nil {
+		t.Fatal(err)
+	}
+	if f.Enabled("user") {
+		t.Error("Enabled() = true for unset flag")
+	}
+}
+
+func TestFlagRollout(t *testing.T) {
+	f := Flag("TEST_FEATURE")
+	count := func() int {
+		n := 0
+		for i := 0; i < 10000; i++ {
+			if f.Enabled(i) {
+				n++
+			}
+		}
+		return n
+	}
+	setAll(t, map[string]string{"TEST_FEATURE": "25%"})
+	n25 := count()
+	if n25 < 2300 || n25 > 2700 {
+		t.Errorf("25%% rollout enabled %d/10000 keys", n25)
+	}
+	enabled := make(map[int]bool)
+	for i := 0; i < 10000; i++ {
+		enabled[i] = f.Enabled(i)
+	}
+	setAll(t, map[string]string{"TEST_FEATURE": "50%"})
+	for i := 0; i < 10000; i++ {
+		if enabled[i] && !f.Enabled(i) {
+			t.Fatalf("key %d disabled when increasing the rollout", i)
+		}
+	}
+	setAll(t, map[string]string{"TEST_FEATURE": "100%"})
+	if n := count(); n != 10000 {
+		t.Errorf("100%% rollout enabled %d/10000 keys", n)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"strings"
+)
+
+// Snapshot is a read-only copy of the environment, see Freeze.
+type Snapshot struct {
+	vars map[string]string
+}
+
+// Freeze returns a Snapshot of the environment of the Default Accessor, which later calls
+// to os.Setenv, Set or Unset do not affect. It is a Source, so that consistent values can be
+// read throughout a request or a test with GetCtx:
+//
+//	s := env.Freeze()
+//	port, err := env.GetCtx[int](ctx, "PORT", s)
+//
+// It is also a read-only Accessor, which can be made the Default one.
+func Freeze() *Snapshot {
+	s := &Snapshot{vars: make(map[string]string)}
+	for _, kv := range Environ() {
+		s.vars[kv.Key] = kv.Value
+	}
+	return s
+}
+
+// Lookup implements Source, matching key case-insensitively if CaseInsensitive is set.
+func (s *Snapshot) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	v, ok := s.lookup(key)
+	return v, ok, nil
+}
+
+func (s *Snapshot) lookup(key string) (string, bool) {
+	if v, ok := s.vars[key]; ok || !CaseInsensitive() {
+		return v, ok
+	}
+	for k, v := range s.vars {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Get returns the raw value of the variable name, after applying the KeyMapper.
+func (s *Snapshot) Get(name string) (string, bool) {
+	return s.lookup(Key(name))
+}
+
+// LookupEnv implements Accessor.
+func (s *Snapshot) LookupEnv(key string) (string, bool) {
+	v, ok := s.vars[key]
+	return v, ok
+}
+
+// Setenv implements Accessor, always failing with ErrReadOnly.
+func (s *Snapshot) Setenv(key, value string) error {
+	return ErrReadOnly
+}
+
+// Unsetenv implements Accessor, always failing with ErrReadOnly.
+func (s *Snapshot) Unsetenv(key string) error {
+	return ErrReadOnly
+}
+
+// Environ implements Accessor, returning the sorted KEY=VALUE entries.
+func (s *Snapshot) Environ() []string {
+	return environ(s.vars)
+}
+
+// Map returns a copy of the snapshot variables.
+func (s *Snapshot) Map() map[string]string {
+	m := make(map[string]string, len(s.vars))
+	for k, v := range s.vars {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	setAll(t, map[string]string{"TEST_FREEZE": "1"})
+	s := Freeze()
+	if err := Set("TEST_FREEZE", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("TEST_FREEZE_NEW", 3); err != nil {
+		t.Fatal(err)
+	}
+	defer Unset("TEST_FREEZE_NEW")
+	if v, err := GetCtx[int](context.Background(), "TEST_FREEZE", s); err != nil || v != 1 {
+		t.Errorf("GetCtx() = %v, %v, want 1", v, err)
+	}
+	if v, ok := s.Get("TEST_FREEZE_NEW"); ok {
+		t.Errorf("Get() = %v, want not set", v)
+	}
+	m := s.Map()
+	m["TEST_FREEZE"] = "4"
+	if v, _ := s.Get("TEST_FREEZE"); v != "1" {
+		t.Errorf("Get() = %v after Map() modification, want 1", v)
+	}
+
+	prev := CaseInsensitive()
+	SetCaseInsensitive(true)
+	defer SetCaseInsensitive(prev)
+	if v, ok := s.Get("test_freeze"); !ok || v != "1" {
+		t.Errorf("Get() = %v, %v, want case-insensitive match", v, ok)
+	}
+}
@@ -1,3 +1,3 @@
 module go.linka.cloud/env
 
-go 1.19
+go 1.21
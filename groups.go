@@ -0,0 +1,84 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+)
+
+// ParseGroups parses the repeated groups of variables sharing the prefix into a map
+// keyed by group name, e.g. with a T struct binding the URL variable,
+// DB_PRIMARY_URL and DB_REPLICA1_URL are parsed with ParseGroups[T]("DB")
+// into the "PRIMARY" and "REPLICA1" entries.
+//
+// Groups are discovered from the variables named <prefix>_<group>_<name>,
+// where name is one of the variables of T, and parsed as by Parse with
+// WithPrefix(<prefix>_<group>).
+func ParseGroups[T any](prefix string, opts ...ParseOption) (map[string]T, error) {
+	var t T
+	s, err := NewSchema(&t)
+	if err != nil {
+		return nil, err
+	}
+	var errs Errors
+	groups := make(map[string]T)
+	for _, g := range groupNames(Default().Environ(), prefix, s) {
+		var t T
+		if err := Parse(&t, append(opts, WithPrefix(prefix+"_"+g))...); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		groups[g] = t
+	}
+	if len(errs) != 0 {
+		return groups, errs
+	}
+	return groups, nil
+}
+
+// groupNames returns the group names of the environ variables matching the schema
+// variables prefixed with prefix.
+func groupNames(environ []string, prefix string, s *Schema) []string {
+	norm := func(s string) string {
+		if CaseInsensitive() {
+			return strings.ToUpper(s)
+		}
+		return s
+	}
+	prefix = norm(Key(prefix)) + "_"
+	var names []string
+	seen := make(map[string]bool)
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		k = norm(k)
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		rest := k[len(prefix):]
+		// the longest variable name wins, e.g. POOL_SIZE over SIZE
+		group := ""
+		for _, v := range s.Vars {
+			suffix := "_" + norm(Key(v.Name))
+			if g, ok := strings.CutSuffix(rest, suffix); ok && g != "" && (group == "" || len(g) < len(group)) {
+				group = g
+			}
+		}
+		if group != "" && !seen[group] {
+			seen[group] = true
+			names = append(names, group)
+		}
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	type db struct {
+		URL      string `env:"URL,required"`
+		PoolSize int    `env:"POOL_SIZE" default:"10"`
+		Size     int    `env:"SIZE"`
+	}
+	setAll(t, map[string]string{
+		"TEST_DB_PRIMARY_URL":       "postgres://primary",
+		"TEST_DB_PRIMARY_POOL_SIZE": "20",
+		"TEST_DB_REPLICA1_URL":      "postgres://replica1",
+		"TEST_DB_REPLICA_EU_URL":    "postgres://replica-eu",
+		"TEST_DB_REPLICA_EU_SIZE":   "1",
+		"TEST_DB_PRIMARY_UNRELATED": "x",
+		"TEST_DBX_OTHER_URL":        "postgres://other",
+	})
+	got, err := ParseGroups[db]("TEST_DB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]db{
+		"PRIMARY":    {URL: "postgres://primary", PoolSize: 20},
+		"REPLICA1":   {URL: "postgres://replica1", PoolSize: 10},
+		"REPLICA_EU": {URL: "postgres://replica-eu", PoolSize: 10, Size: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGroups() = %v, want %v", got, want)
+	}
+
+	setAll(t, map[string]string{"TEST_DB_BROKEN_POOL_SIZE": "x"})
+	got, err = ParseGroups[db]("TEST_DB")
+	if !errors.Is(err, ErrMissing) || len(got) != 3 {
+		t.Errorf("ParseGroups() = %v, %v, want 3 groups and ErrMissing", got, err)
+	}
+	if _, err := ParseGroups[int]("TEST_DB"); err == nil {
+		t.Error("ParseGroups[int]() expected error")
+	}
+}
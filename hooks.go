@@ -0,0 +1,131 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	parseErrorHook atomic.Pointer[func(key, raw string, err error)]
+	missingHook    atomic.Pointer[func(key string)]
+	lookupHook     atomic.Pointer[func(key string, found bool)]
+	sourceHook     atomic.Pointer[func(source string, d time.Duration)]
+	reloadHook     atomic.Pointer[func(t time.Time)]
+	sourceErrHook  atomic.Pointer[func(key string, err error)]
+)
+
+// OnLookup registers fn to be called each time a variable is looked up,
+// e.g. to count the configuration accesses per key.
+// As for the other hooks, key is the variable name mapped by the current KeyMapper.
+// Passing nil removes the hook.
+func OnLookup(fn func(key string, found bool)) {
+	if fn == nil {
+		lookupHook.Store(nil)
+		return
+	}
+	lookupHook.Store(&fn)
+}
+
+// OnParseError registers fn to be called by the getters when a variable is set
+// but its value cannot be parsed, e.g. to log or count malformed configuration.
+// Slice elements are reported with their index, e.g. "HOSTS[2]".
+// Passing nil removes the hook.
+func OnParseError(fn func(key, raw string, err error)) {
+	if fn == nil {
+		parseErrorHook.Store(nil)
+		return
+	}
+	parseErrorHook.Store(&fn)
+}
+
+// OnMissing registers fn to be called by the getters when a variable is not set.
+// Passing nil removes the hook.
+func OnMissing(fn func(key string)) {
+	if fn == nil {
+		missingHook.Store(nil)
+		return
+	}
+	missingHook.Store(&fn)
+}
+
+// OnSourceLookup registers fn to be called after each lookup of a Cache against
+// its underlying source, with the source name and the lookup duration.
+// Passing nil removes the hook.
+func OnSourceLookup(fn func(source string, d time.Duration)) {
+	if fn == nil {
+		sourceHook.Store(nil)
+		return
+	}
+	sourceHook.Store(&fn)
+}
+
+// OnReload registers fn to be called when a Cache refresh completes without error.
+// Passing nil removes the hook.
+func OnReload(fn func(t time.Time)) {
+	if fn == nil {
+		reloadHook.Store(nil)
+		return
+	}
+	reloadHook.Store(&fn)
+}
+
+// OnSourceError registers fn to be called when a Cache fails to refresh key
+// and serves its stale value instead.
+// Passing nil removes the hook.
+func OnSourceError(fn func(key string, err error)) {
+	if fn == nil {
+		sourceErrHook.Store(nil)
+		return
+	}
+	sourceErrHook.Store(&fn)
+}
+
+func parseError(key, raw string, err error) {
+	if fn := parseErrorHook.Load(); fn != nil {
+		(*fn)(key, raw, err)
+	}
+}
+
+func missing(key string) {
+	if fn := missingHook.Load(); fn != nil {
+		(*fn)(key)
+	}
+}
+
+func looked(key string, found bool) {
+	if fn := lookupHook.Load(); fn != nil {
+		(*fn)(key, found)
+	}
+}
+
+func sourceLookup(source string, d time.Duration) {
+	if fn := sourceHook.Load(); fn != nil {
+		(*fn)(source, d)
+	}
+}
+
+func reloaded(t time.Time) {
+	if fn := reloadHook.Load(); fn != nil {
+		(*fn)(t)
+	}
+}
+
+func sourceError(key string, err error) {
+	if fn := sourceErrHook.Load(); fn != nil {
+		(*fn)(key, err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	var missed, failed, looked []string
+	OnLookup(func(key string, found bool) {
+		if found {
+			looked = append(looked, key)
+		}
+	})
+	defer OnLookup(nil)
+	OnMissing(func(key string) {
+		missed = append(missed, key)
+	})
+	OnParseError(func(key, raw string, err error) {
+		failed = append(failed, key+"="+raw)
+	})
+	defer OnMissing(nil)
+	defer OnParseError(nil)
+
+	if err := Unset("TEST_HOOK"); err != nil {
+		t.Fatal(err)
+	}
+	Get[int]("TEST_HOOK")
+	GetDefault("TEST_HOOK", 1)
+	GetSliceDefault("TEST_HOOK", []int{1})
+	if err := Set("TEST_HOOK", "1,x"); err != nil {
+		t.Fatal(err)
+	}
+	defer Unset("TEST_HOOK")
+	Get[int]("TEST_HOOK")
+	GetSliceDefault[int]("TEST_HOOK", nil)
+	GetSlice[int]("TEST_HOOK")
+	if _, err := GetSliceWith[int]("TEST_HOOK", nil, WithSliceMode(SliceStrict)); err == nil {
+		t.Error("GetSliceWith(strict) expected error")
+	}
+
+	if want := "TEST_HOOK,TEST_HOOK,TEST_HOOK,TEST_HOOK"; join(looked, ",") != want {
+		t.Errorf("lookups = %v, want %v", looked, want)
+	}
+	if want := "TEST_HOOK,TEST_HOOK,TEST_HOOK"; join(missed, ",") != want {
+		t.Errorf("missing = %v, want %v", missed, want)
+	}
+	if want := "TEST_HOOK=1,x,TEST_HOOK[1]=x,TEST_HOOK[1]=x"; join(failed, ",") != want {
+		t.Errorf("parse errors = %v, want %v", failed, want)
+	}
+}
+
+func TestHooksMappedKey(t *testing.T) {
+	SetKeyMapper(UpperSnakeCase)
+	defer SetKeyMapper(nil)
+	var looked, missed, failed []string
+	OnLookup(func(key string, _ bool) {
+		looked = append(looked, key)
+	})
+	defer OnLookup(nil)
+	OnMissing(func(key string) {
+		missed = append(missed, key)
+	})
+	defer OnMissing(nil)
+	OnParseError(func(key, _ string, _ error) {
+		failed = append(failed, key)
+	})
+	defer OnParseError(nil)
+
+	if err := Unset("test.hook"); err != nil {
+		t.Fatal(err)
+	}
+	GetDefault("test.hook", 1)
+	if err := Set("test.hook", "x"); err != nil {
+		t.Fatal(err)
+	}
+	defer Unset("test.hook")
+	Get[int]("test.hook")
+	_, err := GetPercent("test.hook", 0, PercentRatio)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "TEST_HOOK" {
+		t.Errorf("GetPercent() error = %v, want TEST_HOOK parse error", err)
+	}
+
+	if want := "TEST_HOOK,TEST_HOOK,TEST_HOOK"; join(looked, ",") != want {
+		t.Errorf("lookups = %v, want %v", looked, want)
+	}
+	if want := "TEST_HOOK"; join(missed, ",") != want {
+		t.Errorf("missing = %v, want %v", missed, want)
+	}
+	if want := "TEST_HOOK"; join(failed, ",") != want {
+		t.Errorf("parse errors = %v, want %v", failed, want)
+	}
+}
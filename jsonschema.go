@@ -0,0 +1,131 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// jsonSchema is a JSON Schema (draft 2020-12) document.
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Default     any                    `json:"default,omitempty"`
+	Minimum     json.Number            `json:"minimum,omitempty"`
+	Maximum     json.Number            `json:"maximum,omitempty"`
+	Items       *jsonSchema            `json:"items,omitempty"`
+	MinItems    int                    `json:"minItems,omitempty"`
+	MaxItems    int                    `json:"maxItems,omitempty"`
+	UniqueItems bool                   `json:"uniqueItems,omitempty"`
+	WriteOnly   bool                   `json:"writeOnly,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema document describing the variables as the properties
+// of an object, with their types, descriptions, defaults and constraints.
+// Secret variables are marked writeOnly.
+func (s *Schema) JSONSchema() ([]byte, error) {
+	doc := &jsonSchema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema, len(s.Vars)),
+	}
+	for _, v := range s.Vars {
+		p := jsonType(v.Type)
+		p.Description = v.Description
+		p.WriteOnly = v.Secret
+		if v.HasDefault {
+			p.Default = jsonDefault(v.Type, v.Default)
+		}
+		if p.Type == "array" {
+			p.MinItems, p.MaxItems, p.UniqueItems = v.opts.slice.minLen, v.opts.slice.maxLen, v.opts.slice.unique
+		}
+		doc.Properties[v.Name] = p
+		if v.Required {
+			doc.Required = append(doc.Required, v.Name)
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+var (
+	typeDuration = reflect.TypeOf(time.Duration(0))
+	typeTime     = reflect.TypeOf(time.Time{})
+)
+
+// jsonType returns the schema of the values of type t.
+func jsonType(t reflect.Type) *jsonSchema {
+	switch t {
+	case typeDuration:
+		// durations are parsed from strings like "1m30s"
+		return &jsonSchema{Type: "string"}
+	case typeTime:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	}
+	if t.Kind() == reflect.Slice && !isValue(t) {
+		return &jsonSchema{Type: "array", Items: jsonType(t.Elem())}
+	}
+	if _, ok := reflect.New(t).Interface().(interface{ UnmarshalText([]byte) error }); ok {
+		return &jsonSchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := &jsonSchema{Type: "integer"}
+		if b := t.Bits(); b < 64 {
+			s.Minimum = json.Number(strconv.FormatInt(-1<<(b-1), 10))
+			s.Maximum = json.Number(strconv.FormatInt(1<<(b-1)-1, 10))
+		}
+		return s
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := &jsonSchema{Type: "integer", Minimum: "0"}
+		if b := t.Bits(); b < 64 {
+			s.Maximum = json.Number(strconv.FormatUint(1<<b-1, 10))
+		}
+		return s
+	default:
+		return &jsonSchema{Type: "string"}
+	}
+}
+
+// jsonDefault returns the default value raw typed as described by jsonType,
+// or raw if it cannot be parsed.
+func jsonDefault(t reflect.Type, raw string) any {
+	if t.Kind() == reflect.Slice && !isValue(t) {
+		elems := splitSlice(raw)
+		out := make([]any, len(elems))
+		for i, e := range elems {
+			out[i] = jsonDefault(t.Elem(), e)
+		}
+		return out
+	}
+	switch jsonType(t).Type {
+	case "boolean", "number", "integer":
+		p := reflect.New(t)
+		if err := parseValue(raw, p.Interface()); err == nil {
+			return p.Elem().Interface()
+		}
+	}
+	return raw
+}
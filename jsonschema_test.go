@@ -0,0 +1,99 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type config struct {
+		Addr    string        `env:"ADDR" default:":8080" desc:"listen address"`
+		Port    uint16        `env:"PORT" default:"80"`
+		Debug   bool          `env:"DEBUG" default:"false"`
+		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+		Since   time.Time     `env:"SINCE"`
+		Ratio   Percent       `env:"RATIO"`
+		Nodes   []int8        `env:"NODES,required,unique,minlen=1,maxlen=3" default:"1,2"`
+		Token   string        `env:"TOKEN,required,secret"`
+	}
+	s, err := NewSchema(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "ADDR": {
+      "type": "string",
+      "description": "listen address",
+      "default": ":8080"
+    },
+    "DEBUG": {
+      "type": "boolean",
+      "default": false
+    },
+    "NODES": {
+      "type": "array",
+      "default": [
+        1,
+        2
+      ],
+      "items": {
+        "type": "integer",
+        "minimum": -128,
+        "maximum": 127
+      },
+      "minItems": 1,
+      "maxItems": 3,
+      "uniqueItems": true
+    },
+    "PORT": {
+      "type": "integer",
+      "default": 80,
+      "minimum": 0,
+      "maximum": 65535
+    },
+    "RATIO": {
+      "type": "string"
+    },
+    "SINCE": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "TIMEOUT": {
+      "type": "string",
+      "default": "5s"
+    },
+    "TOKEN": {
+      "type": "string",
+      "writeOnly": true
+    }
+  },
+  "required": [
+    "NODES",
+    "TOKEN"
+  ]
+}`
+	if string(b) != want {
+		t.Errorf("JSONSchema() =\n%s\nwant\n%s", b, want)
+	}
+}
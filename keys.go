@@ -0,0 +1,72 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// KeyMapper maps a programmatic key name to an environment variable name.
+type KeyMapper func(key string) string
+
+var keyMapper atomic.Pointer[KeyMapper]
+
+// SetKeyMapper sets the KeyMapper applied to every variable name before it is
+// looked up, set or unset. A nil mapper leaves names untouched, which is the default.
+func SetKeyMapper(m KeyMapper) {
+	if m == nil {
+		keyMapper.Store(nil)
+		return
+	}
+	keyMapper.Store(&m)
+}
+
+// Key returns the environment variable name for key using the current KeyMapper.
+func Key(key string) string {
+	if m := keyMapper.Load(); m != nil {
+		return (*m)(key)
+	}
+	return key
+}
+
+// UpperSnakeCase is a KeyMapper converting keys like "server.http.port",
+// "server-http-port" or "serverHTTPPort" to "SERVER_HTTP_PORT".
+func UpperSnakeCase(key string) string {
+	var b strings.Builder
+	rs := []rune(key)
+	sep := func() {
+		if s := b.String(); s != "" && !strings.HasSuffix(s, "_") {
+			b.WriteByte('_')
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '.' || r == '-' || r == '/' || unicode.IsSpace(r):
+			sep()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := rs[i-1]
+			// split "serverPort" and "HTTPPort" but not "HTTP"
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				sep()
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
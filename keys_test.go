@@ -0,0 +1,56 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+)
+
+func TestUpperSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"server.http.port": "SERVER_HTTP_PORT",
+		"server-http-port": "SERVER_HTTP_PORT",
+		"serverHTTPPort":   "SERVER_HTTP_PORT",
+		"Server.HTTP":      "SERVER_HTTP",
+		"db2Host":          "DB2_HOST",
+		"a..b--c":          "A_B_C",
+		"SERVER_HTTP_PORT": "SERVER_HTTP_PORT",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := UpperSnakeCase(in); got != want {
+			t.Errorf("UpperSnakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestKeyMapper(t *testing.T) {
+	SetKeyMapper(UpperSnakeCase)
+	defer SetKeyMapper(nil)
+	if err := Set("test.key-mapper", 42); err != nil {
+		t.Fatal(err)
+	}
+	defer Unset("TEST_KEY_MAPPER")
+	if got := Get[int]("TEST_KEY_MAPPER"); got != 42 {
+		t.Errorf("Get() = %v, want %v", got, 42)
+	}
+	if got := Get[int]("testKeyMapper"); got != 42 {
+		t.Errorf("Get() = %v, want %v", got, 42)
+	}
+	SetKeyMapper(nil)
+	if got := Key("test.key"); got != "test.key" {
+		t.Errorf("Key() = %q, want %q", got, "test.key")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KubernetesEnv writes the container env block referencing the schema variables
+// from the ConfigMap, or the Secret for secret variables, named name,
+// as generated by KubernetesManifests. References of variables that are not required
+// are optional.
+func KubernetesEnv(w io.Writer, s *Schema, name string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("env:\n")
+	for _, v := range s.Vars {
+		ref := "configMapKeyRef"
+		if v.Secret {
+			ref = "secretKeyRef"
+		}
+		fmt.Fprintf(bw, "  - name: %s\n", yamlString(v.Name))
+		bw.WriteString("    valueFrom:\n")
+		fmt.Fprintf(bw, "      %s:\n", ref)
+		fmt.Fprintf(bw, "        name: %s\n", yamlString(name))
+		fmt.Fprintf(bw, "        key: %s\n", yamlString(v.Name))
+		if !v.Required {
+			bw.WriteString("        optional: true\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// KubernetesManifests writes a ConfigMap holding the defaults of the schema variables
+// and a Secret for the secret variables, both named name.
+// Secret variables and variables without default are written as commented placeholders,
+// as an empty value would set the variable instead of leaving it unset.
+// The variables descriptions are written as comments.
+func KubernetesManifests(w io.Writer, s *Schema, name string) error {
+	var plain, secret []Var
+	for _, v := range s.Vars {
+		if v.Secret {
+			secret = append(secret, v)
+		} else {
+			plain = append(plain, v)
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if len(plain) != 0 {
+		writeManifest(bw, "ConfigMap", name, "data", plain)
+	}
+	if len(secret) != 0 {
+		if len(plain) != 0 {
+			bw.WriteString("---\n")
+		}
+		writeManifest(bw, "Secret", name, "stringData", secret)
+	}
+	return bw.Flush()
+}
+
+func writeManifest(w *bufio.Writer, kind, name, field string, vars []Var) {
+	w.WriteString("apiVersion: v1\n")
+	fmt.Fprintf(w, "kind: %s\n", kind)
+	w.WriteString("metadata:\n")
+	fmt.Fprintf(w, "  name: %s\n", yamlString(name))
+	if kind == "Secret" {
+		w.WriteString("type: Opaque\n")
+	}
+	empty := true
+	for _, v := range vars {
+		if !v.Secret && v.HasDefault {
+			empty = false
+		}
+	}
+	if empty {
+		fmt.Fprintf(w, "%s: {}\n", field)
+	} else {
+		fmt.Fprintf(w, "%s:\n", field)
+	}
+	for _, v := range vars {
+		if v.Description != "" {
+			fmt.Fprintf(w, "  # %s\n", strings.ReplaceAll(v.Description, "\n", "\n  # "))
+		}
+		switch {
+		case v.Secret:
+			fmt.Fprintf(w, "  # %s: <secret>\n", yamlString(v.Name))
+		case !v.HasDefault:
+			fmt.Fprintf(w, "  # %s: <unset>\n", yamlString(v.Name))
+		default:
+			fmt.Fprintf(w, "  %s: %s\n", yamlString(v.Name), yamlString(v.Default))
+		}
+	}
+}
+
+// yamlString returns s as a JSON string, which is a valid YAML double quoted scalar.
+func yamlString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
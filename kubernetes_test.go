@@ -0,0 +1,96 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type kubernetesConfig struct {
+	Addr  string `env:"ADDR" default:":8080" desc:"listen address"`
+	Token string `env:"TOKEN,required,secret" default:"dev"`
+	Level string `env:"LEVEL"`
+}
+
+func TestKubernetesEnv(t *testing.T) {
+	s, err := NewSchema(kubernetesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := KubernetesEnv(&buf, s, "app"); err != nil {
+		t.Fatal(err)
+	}
+	want := `env:
+  - name: "ADDR"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "ADDR"
+        optional: true
+  - name: "TOKEN"
+    valueFrom:
+      secretKeyRef:
+        name: "app"
+        key: "TOKEN"
+  - name: "LEVEL"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "LEVEL"
+        optional: true
+`
+	if buf.String() != want {
+		t.Errorf("KubernetesEnv() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestKubernetesManifests(t *testing.T) {
+	s, err := NewSchema(kubernetesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := KubernetesManifests(&buf, s, "app"); err != nil {
+		t.Fatal(err)
+	}
+	want := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  # listen address
+  "ADDR": ":8080"
+  # "LEVEL": <unset>
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "app"
+type: Opaque
+stringData: {}
+  # "TOKEN": <secret>
+`
+	if buf.String() != want {
+		t.Errorf("KubernetesManifests() =\n%s\nwant\n%s", buf.String(), want)
+	}
+	for _, l := range strings.Split(buf.String(), "\n") {
+		if strings.HasSuffix(l, `: ""`) {
+			t.Errorf("KubernetesManifests() sets an empty value: %s", l)
+		}
+	}
+}
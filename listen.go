@@ -0,0 +1,124 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ListenAddr returns the TCP listen address of the variable name, or def if not set.
+// The value may be a host:port address, a bare port like 8080, :0 for a random port,
+// or a port range like 8000-8100 or localhost:8000-8100, in which case the first
+// free port is returned. Prefer Listen with port ranges, as the port may be taken
+// between ListenAddr and the actual bind.
+func ListenAddr(name, def string) (string, error) {
+	addr, err := listenSpec(name, def)
+	if err != nil {
+		return "", err
+	}
+	if addr.from == addr.to {
+		return net.JoinHostPort(addr.host, strconv.Itoa(addr.from)), nil
+	}
+	l, err := addr.listen(name)
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return net.JoinHostPort(addr.host, strconv.Itoa(l.Addr().(*net.TCPAddr).Port)), nil
+}
+
+// Listen binds a TCP listener on the address of the variable name, or def if not set,
+// as accepted by ListenAddr.
+func Listen(name, def string) (net.Listener, error) {
+	addr, err := listenSpec(name, def)
+	if err != nil {
+		return nil, err
+	}
+	return addr.listen(name)
+}
+
+type listenAddr struct {
+	host     string
+	from, to int
+}
+
+// listen binds the first free port of the range.
+func (a listenAddr) listen(name string) (net.Listener, error) {
+	for p := a.from; ; p++ {
+		l, err := net.Listen("tcp", net.JoinHostPort(a.host, strconv.Itoa(p)))
+		if err == nil {
+			return l, nil
+		}
+		if p >= a.to {
+			return nil, fmt.Errorf("env: %s: %w", Key(name), err)
+		}
+	}
+}
+
+func listenSpec(name, def string) (listenAddr, error) {
+	key := Key(name)
+	raw, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		raw = def
+	}
+	a, err := parseListenAddr(raw)
+	if err != nil {
+		return a, &ParseError{Key: key, Value: raw, Err: err}
+	}
+	return a, nil
+}
+
+func parseListenAddr(s string) (listenAddr, error) {
+	var a listenAddr
+	s = strings.TrimSpace(s)
+	port := s
+	if strings.Contains(s, ":") {
+		var err error
+		if a.host, port, err = net.SplitHostPort(s); err != nil {
+			return a, err
+		}
+	}
+	from, to, isRange := strings.Cut(port, "-")
+	var err error
+	if a.from, err = parsePort(from); err != nil {
+		return a, err
+	}
+	a.to = a.from
+	if isRange {
+		if a.to, err = parsePort(to); err != nil {
+			return a, err
+		}
+		if a.to < a.from {
+			return a, fmt.Errorf("invalid port range %s", port)
+		}
+	}
+	return a, nil
+}
+
+func parsePort(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("missing port")
+	}
+	p, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return int(p), nil
+}
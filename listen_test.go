@@ -0,0 +1,74 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestParseListenAddr(t *testing.T) {
+	tests := []struct {
+		in   string
+		want listenAddr
+		err  bool
+	}{
+		{in: ":8080", want: listenAddr{from: 8080, to: 8080}},
+		{in: "8080", want: listenAddr{from: 8080, to: 8080}},
+		{in: ":0", want: listenAddr{}},
+		{in: "127.0.0.1:9000", want: listenAddr{host: "127.0.0.1", from: 9000, to: 9000}},
+		{in: "[::1]:8000-8100", want: listenAddr{host: "::1", from: 8000, to: 8100}},
+		{in: "8000-8100", want: listenAddr{from: 8000, to: 8100}},
+		{in: "8100-8000", err: true},
+		{in: "localhost:", err: true},
+		{in: "70000", err: true},
+		{in: "http", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseListenAddr(tt.in)
+		if (err != nil) != tt.err || (!tt.err && got != tt.want) {
+			t.Errorf("parseListenAddr(%q) = %+v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestListen(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	port := busy.Addr().(*net.TCPAddr).Port
+	if port == 65535 {
+		t.Skip("no room for a port range")
+	}
+	setAll(t, map[string]string{"TEST_ADDR": "127.0.0.1:" + strconv.Itoa(port) + "-" + strconv.Itoa(port+1)})
+	l, err := Listen("TEST_ADDR", "")
+	if err != nil {
+		// the next port may be taken by another process
+		t.Skip(err)
+	}
+	defer l.Close()
+	if got := l.Addr().(*net.TCPAddr).Port; got != port+1 {
+		t.Errorf("Listen() port = %d, want %d", got, port+1)
+	}
+	if _, err := Listen("TEST_ADDR", ""); err == nil {
+		t.Error("Listen() expected error when the whole range is taken")
+	}
+	if got, err := ListenAddr("TEST_MISSING_ADDR", "8080"); err != nil || got != ":8080" {
+		t.Errorf("ListenAddr() = %q, %v, want :8080", got, err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogEffective(t *testing.T) {
+	type config struct {
+		Addr     string `env:"TEST_LOG_ADDR" default:":8080"`
+		Password string `env:"TEST_LOG_PASSWORD,secret"`
+		Token    string `env:"TEST_LOG_TOKEN,secret"`
+		Level    string `env:"TEST_LOG_LEVEL"`
+		DB       string `env:"TEST_LOG_DB"`
+	}
+	setAll(t, map[string]string{"TEST_LOG_PASSWORD": "s3cr3t", "TEST_LOG_DB": "postgres://app:s3cr3t@db:5432/app"})
+	s, err := NewSchema(config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	LogEffective(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})), s)
+	want := []string{
+		`level=INFO msg=env key=TEST_LOG_ADDR value=:8080 source=default default=true`,
+		`level=INFO msg=env key=TEST_LOG_PASSWORD value=****** source=env default=false`,
+		`level=INFO msg=env key=TEST_LOG_TOKEN value="" source=unset default=false`,
+		`level=INFO msg=env key=TEST_LOG_LEVEL value="" source=unset default=false`,
+		`level=INFO msg=env key=TEST_LOG_DB value=postgres://app:xxxxx@db:5432/app source=env default=false`,
+	}
+	if got := strings.TrimSpace(buf.String()); got != strings.Join(want, "\n") {
+		t.Errorf("LogEffective() =\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+	if strings.Contains(buf.String(), "s3cr3t") {
+		t.Error("LogEffective() leaked a secret")
+	}
+}
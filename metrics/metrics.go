@@ -0,0 +1,124 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes Prometheus metrics about the environment variables resolution.
+//
+//	c := metrics.New("myapp")
+//	prometheus.MustRegister(c)
+//	c.Instrument()
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.linka.cloud/env"
+)
+
+// Collector is a prometheus.Collector tracking the variables lookups,
+// parse failures, sources latencies and last reload time.
+type Collector struct {
+	lookups  *prometheus.CounterVec
+	failures *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	reloaded prometheus.Gauge
+}
+
+// New returns a Collector whose metrics are prefixed with namespace.
+func New(namespace string) *Collector {
+	return &Collector{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "env",
+			Name:      "lookups_total",
+			Help:      "Number of environment variable lookups.",
+		}, []string{"key", "found"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "env",
+			Name:      "parse_failures_total",
+			Help:      "Number of environment variable values that failed to parse.",
+		}, []string{"key"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "env",
+			Name:      "source_duration_seconds",
+			Help:      "Duration of the lookups against configuration sources.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"source"}),
+		reloaded: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "env",
+			Name:      "last_reload_timestamp_seconds",
+			Help:      "Unix timestamp of the last configuration reload.",
+		}),
+	}
+}
+
+// Instrument registers the Collector as the env.OnLookup, env.OnParseError,
+// env.OnSourceLookup and env.OnReload hooks, replacing any previously registered ones.
+// Applications using their own hooks should call Lookup, ParseError, ObserveSource
+// and Reloaded from them instead.
+func (c *Collector) Instrument() {
+	env.OnLookup(c.Lookup)
+	env.OnParseError(c.ParseError)
+	env.OnSourceLookup(c.ObserveSource)
+	env.OnReload(c.Reloaded)
+}
+
+// Lookup records a lookup of key.
+func (c *Collector) Lookup(key string, found bool) {
+	f := "false"
+	if found {
+		f = "true"
+	}
+	c.lookups.WithLabelValues(key, f).Inc()
+}
+
+// ParseError records a parse failure of key.
+// Slice element indexes are dropped to keep the labels cardinality bounded.
+func (c *Collector) ParseError(key, _ string, _ error) {
+	if i := strings.IndexByte(key, '['); i > 0 {
+		key = key[:i]
+	}
+	c.failures.WithLabelValues(key).Inc()
+}
+
+// ObserveSource records the duration of a lookup against the named source.
+func (c *Collector) ObserveSource(source string, d time.Duration) {
+	c.latency.WithLabelValues(source).Observe(d.Seconds())
+}
+
+// Reloaded records t as the last configuration reload time.
+func (c *Collector) Reloaded(t time.Time) {
+	c.reloaded.Set(float64(t.UnixNano()) / 1e9)
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.lookups.Describe(ch)
+	c.failures.Describe(ch)
+	c.latency.Describe(ch)
+	c.reloaded.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.lookups.Collect(ch)
+	c.failures.Collect(ch)
+	c.latency.Collect(ch)
+	c.reloaded.Collect(ch)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"go.linka.cloud/env"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("test")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	c.Instrument()
+	defer env.OnLookup(nil)
+	defer env.OnParseError(nil)
+	defer env.OnSourceLookup(nil)
+	defer env.OnReload(nil)
+
+	if err := env.Set("TEST_METRICS", "1,x"); err != nil {
+		t.Fatal(err)
+	}
+	defer env.Unset("TEST_METRICS")
+	env.GetSliceDefault[int]("TEST_METRICS", nil)
+	env.Get[int]("TEST_METRICS_MISSING")
+	cache := env.NewCache(env.SourceFunc(func(context.Context, string) (string, bool, error) {
+		return "1", true, nil
+	}), time.Nanosecond)
+	if _, _, err := cache.Lookup(context.Background(), "TEST_METRICS_SOURCE"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP test_env_lookups_total Number of environment variable lookups.
+# TYPE test_env_lookups_total counter
+test_env_lookups_total{found="false",key="TEST_METRICS_MISSING"} 1
+test_env_lookups_total{found="true",key="TEST_METRICS"} 1
+# HELP test_env_parse_failures_total Number of environment variable values that failed to parse.
+# TYPE test_env_parse_failures_total counter
+test_env_parse_failures_total{key="TEST_METRICS"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"test_env_lookups_total", "test_env_parse_failures_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(c, "test_env_source_duration_seconds"); n != 1 {
+		t.Errorf("source duration series = %d, want 1", n)
+	}
+	if v := testutil.ToFloat64(c.reloaded); v == 0 {
+		t.Error("last reload timestamp not set")
+	}
+}
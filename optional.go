@@ -0,0 +1,96 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+)
+
+// Optional holds a value and whether it was explicitly set, e.g. to only override
+// a setting when the variable is defined:
+//
+//	var c struct {
+//		Workers env.Optional[int] `env:"WORKERS"`
+//	}
+//
+// The zero Optional is not set.
+type Optional[T Value] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional set to v.
+func Some[T Value](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// GetOptional parses the variable name, returning an Optional which is not set
+// if the variable is not set or fails to parse.
+func GetOptional[T Value](name string) Optional[T] {
+	var o Optional[T]
+	key := Key(name)
+	v, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return o
+	}
+	setValue(key, v, &o)
+	return o
+}
+
+// Get returns the value and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// IsSet reports whether the value is set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// OrElse returns the value if set, or def.
+func (o Optional[T]) OrElse(def T) T {
+	if o.set {
+		return o.value
+	}
+	return def
+}
+
+// Ptr returns a pointer to a copy of the value if set, or nil.
+func (o Optional[T]) Ptr() *T {
+	if !o.set {
+		return nil
+	}
+	v := o.value
+	return &v
+}
+
+// UnmarshalText parses text as a T and marks the value as set,
+// leaving o untouched on error.
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	var v T
+	if err := parseRaw(string(text), &v); err != nil {
+		return err
+	}
+	o.value, o.set = v, true
+	return nil
+}
+
+func (o Optional[T]) String() string {
+	if !o.set {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%v", o.value)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptional(t *testing.T) {
+	setAll(t, map[string]string{"TEST_OPT_SET": "42", "TEST_OPT_EMPTY": "", "TEST_OPT_INVALID": "x"})
+	if o := GetOptional[int]("TEST_OPT_SET"); !o.IsSet() || o.OrElse(1) != 42 || *o.Ptr() != 42 {
+		t.Errorf("GetOptional() = %v, want 42", o)
+	}
+	if o := GetOptional[int]("TEST_OPT_UNSET"); o.IsSet() || o.OrElse(1) != 1 || o.Ptr() != nil || o.String() != "<unset>" {
+		t.Errorf("GetOptional() = %v, want unset", o)
+	}
+	if o := GetOptional[int]("TEST_OPT_INVALID"); o.IsSet() {
+		t.Errorf("GetOptional() = %v, want unset on parse error", o)
+	}
+	if v, ok := GetOptional[string]("TEST_OPT_EMPTY").Get(); !ok || v != "" {
+		t.Errorf("GetOptional() = %q, %v, want empty and set", v, ok)
+	}
+	if o := Some(time.Second); o.String() != "1s" {
+		t.Errorf("Some() = %v, want 1s", o)
+	}
+
+	var c struct {
+		Set     Optional[int]           `env:"TEST_OPT_SET"`
+		Unset   Optional[time.Duration] `env:"TEST_OPT_UNSET"`
+		Default Optional[bool]          `env:"TEST_OPT_UNSET" default:"true"`
+	}
+	if err := Parse(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Set.OrElse(0) != 42 || c.Unset.IsSet() || !c.Default.OrElse(false) {
+		t.Errorf("Parse() = %+v", c)
+	}
+	setAll(t, map[string]string{"TEST_OPT_SET": "x"})
+	if err := Parse(&c); err == nil {
+		t.Error("Parse() expected error")
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otel reads the OpenTelemetry SDK configuration from the OTEL_* environment
+// variables, as defined by the OpenTelemetry specification.
+package otel
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"go.linka.cloud/env"
+)
+
+// Protocols of the OTLP exporters.
+const (
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+	ProtocolHTTPJSON     = "http/json"
+)
+
+// Config is the OpenTelemetry SDK configuration, see Parse.
+type Config struct {
+	Disabled    bool   `env:"OTEL_SDK_DISABLED"`
+	ServiceName string `env:"OTEL_SERVICE_NAME"`
+	// ResourceAttributes includes the service.name attribute when ServiceName is set.
+	ResourceAttributes Map      `env:"OTEL_RESOURCE_ATTRIBUTES"`
+	Propagators        []string `env:"OTEL_PROPAGATORS" default:"tracecontext,baggage"`
+	TracesSampler      string   `env:"OTEL_TRACES_SAMPLER" default:"parentbased_always_on"`
+	TracesSamplerArg   string   `env:"OTEL_TRACES_SAMPLER_ARG"`
+	TracesExporter     []string `env:"OTEL_TRACES_EXPORTER" default:"otlp"`
+	MetricsExporter    []string `env:"OTEL_METRICS_EXPORTER" default:"otlp"`
+	LogsExporter       []string `env:"OTEL_LOGS_EXPORTER" default:"otlp"`
+
+	// OTLP holds the settings shared by the signals exporters.
+	OTLP OTLP `env:"OTEL_EXPORTER_OTLP"`
+	// Traces, Metrics and Logs are the signals exporters settings,
+	// falling back to the OTLP ones.
+	Traces  OTLP `env:"OTEL_EXPORTER_OTLP_TRACES"`
+	Metrics OTLP `env:"OTEL_EXPORTER_OTLP_METRICS"`
+	Logs    OTLP `env:"OTEL_EXPORTER_OTLP_LOGS"`
+}
+
+// OTLP is the configuration of an OTLP exporter.
+type OTLP struct {
+	Endpoint    string `env:"ENDPOINT"`
+	Protocol    string `env:"PROTOCOL"`
+	Headers     Map    `env:"HEADERS"`
+	Compression string `env:"COMPRESSION"`
+	Certificate string `env:"CERTIFICATE"`
+	Insecure    bool   `env:"INSECURE"`
+	// Timeout is read as milliseconds, as specified, or as a duration string.
+	Timeout time.Duration `env:"TIMEOUT"`
+}
+
+// Parse reads the OTEL_* variables.
+//
+// Unset settings of the signals exporters are inherited from the OTEL_EXPORTER_OTLP_*
+// variables. When inherited with an HTTP protocol, the endpoint is suffixed with
+// the signal path, e.g. /v1/traces.
+// The protocol defaults to http/protobuf, the timeout to 10s and the endpoint
+// to http://localhost:4317 for grpc, or http://localhost:4318 otherwise.
+func Parse() (Config, error) {
+	var c Config
+	if err := env.Parse(&c); err != nil {
+		return c, err
+	}
+	if c.ServiceName != "" {
+		if c.ResourceAttributes == nil {
+			c.ResourceAttributes = make(Map)
+		}
+		c.ResourceAttributes["service.name"] = c.ServiceName
+	}
+	if c.OTLP.Protocol == "" {
+		c.OTLP.Protocol = ProtocolHTTPProtobuf
+	}
+	if c.OTLP.Timeout == 0 {
+		c.OTLP.Timeout = 10 * time.Second
+	}
+	if err := c.OTLP.check("OTEL_EXPORTER_OTLP"); err != nil {
+		return c, err
+	}
+	for _, s := range []struct {
+		name string
+		otlp *OTLP
+	}{{"traces", &c.Traces}, {"metrics", &c.Metrics}, {"logs", &c.Logs}} {
+		s.otlp.inherit(c.OTLP, s.name)
+		if err := s.otlp.check("OTEL_EXPORTER_OTLP_" + strings.ToUpper(s.name)); err != nil {
+			return c, err
+		}
+	}
+	if c.OTLP.Endpoint == "" {
+		c.OTLP.Endpoint = defaultEndpoint(c.OTLP.Protocol)
+	}
+	return c, nil
+}
+
+// inherit sets the unset fields of o from base, appending the signal path
+// to the base HTTP endpoint.
+func (o *OTLP) inherit(base OTLP, signal string) {
+	if o.Protocol == "" {
+		o.Protocol = base.Protocol
+	}
+	if o.Endpoint == "" {
+		o.Endpoint = base.Endpoint
+		if o.Endpoint == "" {
+			o.Endpoint = defaultEndpoint(o.Protocol)
+		}
+		if o.Protocol != ProtocolGRPC {
+			o.Endpoint = strings.TrimSuffix(o.Endpoint, "/") + "/v1/" + signal
+		}
+	}
+	if o.Headers == nil {
+		o.Headers = base.Headers
+	}
+	if o.Compression == "" {
+		o.Compression = base.Compression
+	}
+	if o.Certificate == "" {
+		o.Certificate = base.Certificate
+	}
+	if !o.Insecure {
+		o.Insecure = base.Insecure
+	}
+	if o.Timeout == 0 {
+		o.Timeout = base.Timeout
+	}
+}
+
+func (o *OTLP) check(prefix string) error {
+	switch o.Protocol {
+	case ProtocolGRPC, ProtocolHTTPProtobuf, ProtocolHTTPJSON:
+	default:
+		return &env.ParseError{Key: prefix + "_PROTOCOL", Value: o.Protocol, Err: fmt.Errorf("unsupported protocol")}
+	}
+	if o.Endpoint == "" {
+		return nil
+	}
+	if _, err := url.Parse(o.Endpoint); err != nil {
+		return &env.ParseError{Key: prefix + "_ENDPOINT", Value: o.Endpoint, Err: err}
+	}
+	return nil
+}
+
+func defaultEndpoint(protocol string) string {
+	if protocol == ProtocolGRPC {
+		return "http://localhost:4317"
+	}
+	return "http://localhost:4318"
+}
+
+// Map is a list of comma separated key=value pairs, as used by OTEL_RESOURCE_ATTRIBUTES
+// and OTEL_EXPORTER_OTLP_HEADERS, following the W3C Baggage format:
+// values are percent-decoded and the ;-separated properties are ignored.
+type Map map[string]string
+
+func (m *Map) UnmarshalText(text []byte) error {
+	out := make(Map)
+	for _, kv := range strings.Split(string(text), ",") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		kv, _, _ = strings.Cut(kv, ";")
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return fmt.Errorf("invalid key=value pair %q", kv)
+		}
+		v, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		out[k] = v
+	}
+	*m = out
+	return nil
+}
+
+// String returns the pairs sorted by key, with their values percent-encoded.
+func (m Map) String() string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + url.PathEscape(m[k])
+	}
+	return strings.Join(keys, ",")
+}
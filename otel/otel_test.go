@@ -0,0 +1,103 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otel
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func clearEnv(t *testing.T) {
+	for _, kv := range os.Environ() {
+		if k, _, _ := strings.Cut(kv, "="); strings.HasPrefix(k, "OTEL_") {
+			t.Setenv(k, "")
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	clearEnv(t)
+	c, err := Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.OTLP.Endpoint != "http://localhost:4318" || c.OTLP.Protocol != ProtocolHTTPProtobuf || c.OTLP.Timeout != 10*time.Second {
+		t.Errorf("OTLP = %+v", c.OTLP)
+	}
+	if c.Traces.Endpoint != "http://localhost:4318/v1/traces" || c.Logs.Endpoint != "http://localhost:4318/v1/logs" {
+		t.Errorf("Traces = %+v, Logs = %+v", c.Traces, c.Logs)
+	}
+	if !reflect.DeepEqual(c.Propagators, []string{"tracecontext", "baggage"}) || c.TracesSampler != "parentbased_always_on" {
+		t.Errorf("Config = %+v", c)
+	}
+}
+
+func TestParse(t *testing.T) {
+	clearEnv(t)
+	for k, v := range map[string]string{
+		"OTEL_SERVICE_NAME":                   "api",
+		"OTEL_RESOURCE_ATTRIBUTES":            "deployment.environment=prod, team=a%20b;meta",
+		"OTEL_PROPAGATORS":                    "b3,tracecontext",
+		"OTEL_EXPORTER_OTLP_ENDPOINT":         "https://collector:4318/",
+		"OTEL_EXPORTER_OTLP_HEADERS":          "api-key=s3cr3t,x-tenant=1",
+		"OTEL_EXPORTER_OTLP_TIMEOUT":          "500",
+		"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL": "grpc",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT": "collector:4317",
+		"OTEL_EXPORTER_OTLP_METRICS_HEADERS":  "api-key=other",
+	} {
+		t.Setenv(k, v)
+	}
+	c, err := Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Map{"service.name": "api", "deployment.environment": "prod", "team": "a b"}); !reflect.DeepEqual(c.ResourceAttributes, want) {
+		t.Errorf("ResourceAttributes = %v, want %v", c.ResourceAttributes, want)
+	}
+	if want := "deployment.environment=prod,service.name=api,team=a%20b"; c.ResourceAttributes.String() != want {
+		t.Errorf("ResourceAttributes.String() = %s, want %s", c.ResourceAttributes, want)
+	}
+	want := OTLP{
+		Endpoint: "https://collector:4318/v1/traces",
+		Protocol: ProtocolHTTPProtobuf,
+		Headers:  Map{"api-key": "s3cr3t", "x-tenant": "1"},
+		Timeout:  500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(c.Traces, want) {
+		t.Errorf("Traces = %+v, want %+v", c.Traces, want)
+	}
+	want = OTLP{
+		Endpoint: "collector:4317",
+		Protocol: ProtocolGRPC,
+		Headers:  Map{"api-key": "other"},
+		Timeout:  500 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(c.Metrics, want) {
+		t.Errorf("Metrics = %+v, want %+v", c.Metrics, want)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL", "thrift")
+	if _, err := Parse(); err == nil || !strings.Contains(err.Error(), "OTEL_EXPORTER_OTLP_LOGS_PROTOCOL") {
+		t.Errorf("Parse() error = %v, want unsupported protocol", err)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "novalue")
+	if _, err := Parse(); err == nil {
+		t.Error("Parse() expected error")
+	}
+}
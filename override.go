@@ -0,0 +1,74 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"sort"
+)
+
+// WithValues sets the variables of values, using the Default Accessor, for the duration
+// of fn, restoring their previous values or unsetting them afterward, even if fn panics.
+// Calls can be nested, the inner values overriding the outer ones.
+// The values are visible to the other goroutines and, with the Process accessor,
+// to the commands started by fn.
+func WithValues(values map[string]string, fn func()) error {
+	return WithValuesContext(context.Background(), values, func(context.Context) error {
+		fn()
+		return nil
+	})
+}
+
+// WithValuesContext is like WithValues but passes ctx to fn and returns its error.
+// fn is not called if ctx is already done.
+func WithValuesContext(ctx context.Context, values map[string]string, fn func(ctx context.Context) error) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	type prev struct {
+		key, value string
+		ok         bool
+	}
+	a := Default()
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var restore []prev
+	defer func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			p := restore[i]
+			var rerr error
+			if p.ok {
+				rerr = a.Setenv(p.key, p.value)
+			} else {
+				rerr = a.Unsetenv(p.key)
+			}
+			if err == nil {
+				err = rerr
+			}
+		}
+	}()
+	for _, k := range keys {
+		key := Key(k)
+		v, ok := a.LookupEnv(key)
+		if err := a.Setenv(key, values[k]); err != nil {
+			return err
+		}
+		restore = append(restore, prev{key: key, value: v, ok: ok})
+	}
+	return fn(ctx)
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestWithValues(t *testing.T) {
+	setAll(t, map[string]string{"TEST_WITH_SET": "outer"})
+	os.Unsetenv("TEST_WITH_UNSET")
+	err := WithValues(map[string]string{"TEST_WITH_SET": "1", "TEST_WITH_UNSET": "2"}, func() {
+		if v := Get[int]("TEST_WITH_SET"); v != 1 {
+			t.Errorf("Get() = %v, want 1", v)
+		}
+		err := WithValues(map[string]string{"TEST_WITH_SET": "3"}, func() {
+			if v := Get[int]("TEST_WITH_SET"); v != 3 {
+				t.Errorf("nested Get() = %v, want 3", v)
+			}
+			if v := Get[int]("TEST_WITH_UNSET"); v != 2 {
+				t.Errorf("nested Get() = %v, want 2", v)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := Get[int]("TEST_WITH_SET"); v != 1 {
+			t.Errorf("Get() = %v after nested call, want 1", v)
+		}
+		if _, err := exec.LookPath("sh"); err == nil {
+			out, err := exec.Command("sh", "-c", "printf %s $TEST_WITH_UNSET").Output()
+			if err != nil || string(out) != "2" {
+				t.Errorf("child process got %q, %v, want 2", out, err)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := os.Getenv("TEST_WITH_SET"); v != "outer" {
+		t.Errorf("TEST_WITH_SET = %q, want outer", v)
+	}
+	if _, ok := os.LookupEnv("TEST_WITH_UNSET"); ok {
+		t.Error("TEST_WITH_UNSET was not unset")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		WithValues(map[string]string{"TEST_WITH_SET": "panic"}, func() {
+			panic("boom")
+		})
+	}()
+	if v := os.Getenv("TEST_WITH_SET"); v != "outer" {
+		t.Errorf("TEST_WITH_SET = %q after panic, want outer", v)
+	}
+}
+
+func TestWithValuesContext(t *testing.T) {
+	boom := errors.New("boom")
+	err := WithValuesContext(context.Background(), map[string]string{"TEST_WITH_CTX": "1"}, func(ctx context.Context) error {
+		if v, err := GetCtx[int](ctx, "TEST_WITH_CTX"); err != nil || v != 1 {
+			t.Errorf("GetCtx() = %v, %v, want 1", v, err)
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("WithValuesContext() error = %v, want boom", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	if err := WithValuesContext(ctx, nil, func(context.Context) error {
+		called = true
+		return nil
+	}); !errors.Is(err, context.Canceled) || called {
+		t.Errorf("WithValuesContext() = %v, called %v, want context.Canceled", err, called)
+	}
+	defer SetDefault(SetDefault(Freeze()))
+	if err := WithValues(map[string]string{"TEST_WITH_CTX": "1"}, func() {}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("WithValues() error = %v, want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Parse populates the exported fields of the struct pointed to by v from the environment.
+//
+// Fields are mapped to variables using the env struct tag, followed by comma separated options:
+//
+//	type Config struct {
+//		Addr  string   `env:"ADDR" default:":8080" desc:"listen address"`
+//		Nodes []string `env:"SEED_NODES,required,unique,minlen=1,maxlen=5"`
+//		HTTP  struct {
+//			Port uint16 `env:"PORT"` // HTTP_PORT
+//		} `env:"HTTP"`
+//	}
+//
+// The default tag is used when the variable is not set, the desc tag documents the variable.
+// The secret option marks values that must not be displayed, see LogEffective.
+// Required variables that are not set are prompted for if a Prompter is set, see SetPrompter.
+// Nested structs are parsed recursively, their variables prefixed with the struct's env tag
+// followed by an underscore. Other fields without env tag are ignored.
+//
+// Variables can depend on other ones, referenced by name, relative to the nested struct
+// if it binds the variable:
+//   - required_if=TLS_ENABLED makes the variable required when TLS_ENABLED is true,
+//     and required_if=MODE=prod when MODE is prod
+//   - default_from=ADDR uses the value of ADDR, or its default, as default
+//
+// Defaults can also be computed, see WithDefaultFunc.
+//
+// Slices are parsed as comma separated lists in SliceStrict mode.
+// All the fields are processed and the failures are returned as Errors.
+func Parse(v any, opts ...ParseOption) error {
+	var o parseOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: Parse expects a non-nil struct pointer, got %T", v)
+	}
+	s, err := newSchema(v, o.prefix)
+	if err != nil {
+		return err
+	}
+	return s.decode(rv.Elem(), &o, func(f Var) (string, bool) {
+		return lookup(f.Name)
+	})
+}
+
+// decode populates the struct rv from the variables values returned by lookup,
+// running the validator once all the fields are set.
+func (s *Schema) decode(rv reflect.Value, o *parseOptions, lookup func(f Var) (string, bool)) error {
+	errs := make([]error, len(s.Vars))
+	keys := make(map[string]string, len(s.Vars))
+	for _, f := range s.Vars {
+		keys[f.Field] = Key(f.Name)
+	}
+	for i, f := range s.Vars {
+		errs[i] = s.decodeField(rv, f, o, lookup)
+	}
+	var failed Errors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) != 0 {
+		return failed
+	}
+	if o.validator == nil {
+		return nil
+	}
+	if err := o.validator.Struct(rv.Addr().Interface()); err != nil {
+		return validationErrors(err, keys)
+	}
+	return nil
+}
+
+// decodeField sets the field of rv bound to f, applying its dependencies
+// to the other variables.
+func (s *Schema) decodeField(rv reflect.Value, f Var, o *parseOptions, lookup func(f Var) (string, bool)) error {
+	raw, ok := lookup(f)
+	if !ok {
+		if fn, ok := o.defaults[f.Name]; ok {
+			def, err := fn()
+			if err != nil {
+				return fmt.Errorf("env: %s: default: %w", f.Name, err)
+			}
+			f.Default, f.HasDefault = def, true
+		}
+		if f.DefaultFrom != "" {
+			if def, ok := s.value(f.DefaultFrom, lookup); ok {
+				f.Default, f.HasDefault = def, true
+			}
+		}
+	}
+	if f.RequiredIf != "" && !f.opts.required {
+		name, want, cmp := strings.Cut(f.RequiredIf, "=")
+		v, ok := s.value(name, lookup)
+		var on bool
+		if cmp {
+			on = ok && v == want
+		} else {
+			on = ok && parseValue(v, &on) == nil && on
+		}
+		f.Required, f.opts.required = on, on
+	}
+	return setField(rv.FieldByIndex(f.index), f, raw, ok)
+}
+
+// value returns the raw value of the variable name, or its default if it is a
+// variable of the schema.
+func (s *Schema) value(name string, lookup func(f Var) (string, bool)) (string, bool) {
+	v, ok := s.Lookup(name)
+	if !ok {
+		return lookup(Var{Name: name})
+	}
+	if raw, ok := lookup(v); ok {
+		return raw, true
+	}
+	return v.Default, v.HasDefault
+}
+
+// ParseOption configures Parse.
+type ParseOption func(o *parseOptions)
+
+type parseOptions struct {
+	validator Validator
+	prefix    string
+	defaults  map[string]func() (string, error)
+}
+
+// WithPrefix prefixes all the variable names with prefix followed by an underscore,
+// e.g. WithPrefix("UPSTREAM") reads the TIMEOUT field from UPSTREAM_TIMEOUT.
+func WithPrefix(prefix string) ParseOption {
+	return func(o *parseOptions) {
+		o.prefix = prefixed(prefix, "")
+	}
+}
+
+// prefixed returns name prefixed with prefix followed by an underscore,
+// or name as is if prefix is empty.
+func prefixed(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// WithValidator runs v on the populated struct once all the variables are parsed.
+func WithValidator(v Validator) ParseOption {
+	return func(o *parseOptions) {
+		o.validator = v
+	}
+}
+
+// WithDefaultFunc computes the default of the variable name, including its prefixes,
+// with fn when the variable is not set. It takes precedence over the default tag.
+func WithDefaultFunc(name string, fn func() (string, error)) ParseOption {
+	return func(o *parseOptions) {
+		if o.defaults == nil {
+			o.defaults = make(map[string]func() (string, error))
+		}
+		o.defaults[name] = fn
+	}
+}
+
+func parseField(fv reflect.Value, f Var) error {
+	raw, ok := lookup(f.Name)
+	return setField(fv, f, raw, ok)
+}
+
+// setField sets fv from the raw value of the variable f, if ok, or from its default.
+func setField(fv reflect.Value, f Var, raw string, ok bool) error {
+	key, o := Key(f.Name), f.opts
+	if !ok && f.HasDefault {
+		raw, ok = f.Default, true
+	}
+	isSlice := fv.Kind() == reflect.Slice && !isValue(fv.Type())
+	if (isSlice && !isValue(fv.Type().Elem())) || (!isSlice && !isValue(fv.Type())) {
+		return fmt.Errorf("env: %s: %w %s", key, errUnsupportedType, fv.Type())
+	}
+	if !ok && o.required {
+		var err error
+		if raw, ok, err = prompt(f); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		if o.required {
+			return fmt.Errorf("%w: %s", ErrMissing, key)
+		}
+		if isSlice {
+			return o.slice.check(key, nil)
+		}
+		return nil
+	}
+	if !isSlice {
+		p := reflect.New(fv.Type())
+		if err := parseValue(raw, p.Interface()); err != nil {
+			return &ParseError{Key: key, Value: raw, Err: err}
+		}
+		fv.Set(p.Elem())
+		return nil
+	}
+	elems, idx := splitIndexed(raw)
+	if o.required && len(elems) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissing, key)
+	}
+	s := reflect.MakeSlice(fv.Type(), len(elems), len(elems))
+	vals := make([]string, len(elems))
+	for i, e := range elems {
+		if err := parseRaw(e, s.Index(i).Addr().Interface()); err != nil {
+			return &ParseError{Key: fmt.Sprintf("%s[%d]", key, idx[i]), Value: e, Err: err}
+		}
+		vals[i] = fmt.Sprintf("%v", s.Index(i).Interface())
+	}
+	if err := o.slice.check(key, vals); err != nil {
+		return err
+	}
+	fv.Set(s)
+	return nil
+}
+
+// GetAs parses the variable name into out, which must be a pointer to a Value,
+// to a type whose underlying type is a basic kind, like type Port uint16,
+// to an encoding.TextUnmarshaler, or to a slice of those.
+// Slices are parsed as comma separated lists in SliceStrict mode.
+// out is left untouched if the variable is not set.
+func GetAs(name string, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("env: GetAs expects a non-nil pointer, got %T", out)
+	}
+	return parseField(rv.Elem(), Var{Name: name, Type: rv.Elem().Type()})
+}
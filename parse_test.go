@@ -0,0 +1,279 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Addr    string        `env:"TEST_PARSE_ADDR" default:":8080"`
+	Timeout time.Duration `env:"TEST_PARSE_TIMEOUT" default:"5s"`
+	Nodes   []string      `env:"TEST_PARSE_NODES,required,unique,minlen=1,maxlen=3"`
+	IP      net.IP        `env:"TEST_PARSE_IP"`
+	Since   time.Time     `env:"TEST_PARSE_SINCE"`
+	HTTP    struct {
+		Port uint16 `env:"PORT" default:"80"`
+	} `env:"TEST_PARSE_HTTP"`
+	Ignored string
+	skipped string `env:"TEST_PARSE_SKIPPED"`
+}
+
+func setAll(t *testing.T, kv map[string]string) {
+	t.Helper()
+	for k, v := range kv {
+		if err := Set(k, v); err != nil {
+			t.Fatal(err)
+		}
+		k := k
+		t.Cleanup(func() { Unset(k) })
+	}
+}
+
+func TestParse(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_PARSE_NODES":     "a, b",
+		"TEST_PARSE_IP":        "10.0.0.1",
+		"TEST_PARSE_HTTP_PORT": "8081",
+		"TEST_PARSE_SKIPPED":   "nope",
+	})
+	var c testConfig
+	if err := Parse(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Addr != ":8080" || c.Timeout != 5*time.Second {
+		t.Errorf("Parse() defaults = %q, %v", c.Addr, c.Timeout)
+	}
+	if join(c.Nodes, ",") != "a,b" {
+		t.Errorf("Parse() Nodes = %v", c.Nodes)
+	}
+	if c.IP.String() != "10.0.0.1" || c.HTTP.Port != 8081 || c.skipped != "" {
+		t.Errorf("Parse() = %+v", c)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes string
+		want  string
+	}{
+		{name: "missing", want: "required variable not set"},
+		{name: "empty", nodes: ",", want: "required variable not set"},
+		{name: "duplicate", nodes: "a,b,a", want: "duplicate element"},
+		{name: "too many", nodes: "a,b,c,d", want: "at most 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.nodes != "" {
+				setAll(t, map[string]string{"TEST_PARSE_NODES": tt.nodes})
+			}
+			setAll(t, map[string]string{"TEST_PARSE_TIMEOUT": "nope"})
+			var c testConfig
+			err := Parse(&c)
+			var errs Errors
+			if !errors.As(err, &errs) || len(errs) != 2 {
+				t.Fatalf("Parse() error = %v, want 2 errors", err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Key != "TEST_PARSE_TIMEOUT" {
+				t.Errorf("Parse() error = %v, want TEST_PARSE_TIMEOUT parse error", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if err := Parse(testConfig{}); err == nil {
+		t.Error("Parse(struct) expected error")
+	}
+}
+
+type testFieldError struct {
+	ns, tag, param string
+}
+
+func (e testFieldError) Error() string {
+	return "Key: '" + e.ns + "' Error: failed on the '" + e.tag + "' tag"
+}
+func (e testFieldError) StructNamespace() string { return e.ns }
+func (e testFieldError) Tag() string             { return e.tag }
+func (e testFieldError) Param() string           { return e.param }
+
+type testFieldErrors []testFieldError
+
+func (e testFieldErrors) Error() string { return "validation failed" }
+
+func TestParseValidator(t *testing.T) {
+	setAll(t, map[string]string{"TEST_PARSE_NODES": "a"})
+	var c testConfig
+	v := ValidatorFunc(func(v any) error {
+		if v.(*testConfig).HTTP.Port < 1024 {
+			return testFieldErrors{{ns: "testConfig.HTTP.Port", tag: "min", param: "1024"}}
+		}
+		return nil
+	})
+	err := Parse(&c, WithValidator(v))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Key != "TEST_PARSE_HTTP_PORT" || verr.Field != "HTTP.Port" {
+		t.Fatalf("Parse() error = %v, want TEST_PARSE_HTTP_PORT validation error", err)
+	}
+	if want := `env: TEST_PARSE_HTTP_PORT: validation failed on "min" (1024)`; err.Error() != want {
+		t.Errorf("Parse() error = %q, want %q", err, want)
+	}
+
+	type mapped struct {
+		Port int `env:"test.parse.port"`
+	}
+	SetKeyMapper(UpperSnakeCase)
+	defer SetKeyMapper(nil)
+	err = Parse(&mapped{}, WithValidator(ValidatorFunc(func(any) error {
+		return testFieldErrors{{ns: "mapped.Port", tag: "required"}}
+	})))
+	if !errors.As(err, &verr) || verr.Key != "TEST_PARSE_PORT" {
+		t.Errorf("Parse() error = %v, want TEST_PARSE_PORT validation error", err)
+	}
+	SetKeyMapper(nil)
+
+	plain := errors.New("invalid config")
+	if err := Parse(&c, WithValidator(ValidatorFunc(func(any) error { return plain }))); err != plain {
+		t.Errorf("Parse() error = %v, want %v", err, plain)
+	}
+}
+
+func TestNewSchema(t *testing.T) {
+	s, err := NewSchema(&testConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, v := range s.Vars {
+		names = append(names, v.Name)
+	}
+	want := "TEST_PARSE_ADDR,TEST_PARSE_TIMEOUT,TEST_PARSE_NODES,TEST_PARSE_IP,TEST_PARSE_SINCE,TEST_PARSE_HTTP_PORT"
+	if join(names, ",") != want {
+		t.Errorf("NewSchema() vars = %v, want %v", names, want)
+	}
+	v, ok := s.Lookup("TEST_PARSE_HTTP_PORT")
+	if !ok || v.Field != "HTTP.Port" || v.Default != "80" || !v.HasDefault {
+		t.Errorf("Lookup() = %+v, %v", v, ok)
+	}
+	if _, err := NewSchema(struct {
+		A string `env:"A,nope"`
+	}{}); err == nil {
+		t.Error("NewSchema() expected unknown option error")
+	}
+	if _, err := NewSchema(42); err == nil {
+		t.Error("NewSchema(int) expected error")
+	}
+}
+
+type testPort uint16
+
+type testRegion string
+
+type testRegions []testRegion
+
+func TestGetAs(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_GETAS_PORT":    "8080",
+		"TEST_GETAS_REGIONS": "eu-west-1, us-east-1",
+		"TEST_GETAS_BAD":     "70000",
+	})
+	var port testPort
+	if err := GetAs("TEST_GETAS_PORT", &port); err != nil || port != 8080 {
+		t.Errorf("GetAs() = %v, %v, want 8080", port, err)
+	}
+	var regions testRegions
+	if err := GetAs("TEST_GETAS_REGIONS", &regions); err != nil || join(regions, ",") != "eu-west-1,us-east-1" {
+		t.Errorf("GetAs() = %v, %v", regions, err)
+	}
+	var perr *ParseError
+	if err := GetAs("TEST_GETAS_BAD", &port); !errors.As(err, &perr) || port != 8080 {
+		t.Errorf("GetAs() = %v, %v, want parse error", port, err)
+	}
+	d := 5 * time.Second
+	if err := GetAs("TEST_GETAS_MISSING", &d); err != nil || d != 5*time.Second {
+		t.Errorf("GetAs() = %v, %v, want untouched", d, err)
+	}
+	if err := GetAs("TEST_GETAS_PORT", port); err == nil {
+		t.Error("GetAs(non pointer) expected error")
+	}
+	var m map[string]string
+	if err := GetAs("TEST_GETAS_PORT", &m); !errors.Is(err, errUnsupportedType) {
+		t.Errorf("GetAs(map) error = %v, want unsupported type", err)
+	}
+}
+
+func TestParseDependencies(t *testing.T) {
+	type config struct {
+		TLS struct {
+			Enabled bool   `env:"ENABLED"`
+			Cert    string `env:"CERT,required_if=ENABLED"`
+		} `env:"TEST_DEP_TLS"`
+		Mode      string `env:"TEST_DEP_MODE" default:"dev"`
+		Token     string `env:"TEST_DEP_TOKEN,required_if=TEST_DEP_MODE=prod"`
+		Addr      string `env:"TEST_DEP_ADDR" default:":8080"`
+		AdminAddr string `env:"TEST_DEP_ADMIN_ADDR,default_from=TEST_DEP_ADDR"`
+		Workers   int    `env:"TEST_DEP_WORKERS" default:"1"`
+	}
+	setAll(t, map[string]string{"TEST_DEP_TLS_ENABLED": "false", "TEST_DEP_MODE": "", "TEST_DEP_ADDR": ""})
+	workers := WithDefaultFunc("TEST_DEP_WORKERS", func() (string, error) {
+		return "8", nil
+	})
+	var c config
+	if err := Parse(&c, workers); err != nil {
+		t.Fatal(err)
+	}
+	if c.AdminAddr != "" || c.Workers != 8 {
+		t.Errorf("Parse() = %+v", c)
+	}
+	setAll(t, map[string]string{"TEST_DEP_TLS_ENABLED": "true", "TEST_DEP_MODE": "prod", "TEST_DEP_ADDR": ":9090"})
+	err := Parse(&c)
+	if !errors.Is(err, ErrMissing) || !strings.Contains(err.Error(), "TEST_DEP_TLS_CERT") || !strings.Contains(err.Error(), "TEST_DEP_TOKEN") {
+		t.Errorf("Parse() error = %v, want TEST_DEP_TLS_CERT and TEST_DEP_TOKEN missing", err)
+	}
+	if c.AdminAddr != ":9090" {
+		t.Errorf("AdminAddr = %q, want :9090", c.AdminAddr)
+	}
+	if err := Parse(&c, WithDefaultFunc("TEST_DEP_WORKERS", func() (string, error) {
+		return "", errors.New("boom")
+	})); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Parse() error = %v, want boom", err)
+	}
+	if _, err := NewSchema(struct {
+		A string `env:"A,default_from="`
+	}{}); err == nil {
+		t.Error("NewSchema() expected error")
+	}
+}
+
+func TestParseSliceIndex(t *testing.T) {
+	setAll(t, map[string]string{"TEST_PARSE_INDEX": "1,,x"})
+	var c struct {
+		Index []int `env:"TEST_PARSE_INDEX"`
+	}
+	var perr *ParseError
+	if err := Parse(&c); !errors.As(err, &perr) || perr.Key != "TEST_PARSE_INDEX[2]" {
+		t.Errorf("Parse() error = %v, want TEST_PARSE_INDEX[2] parse error", err)
+	}
+	if _, err := GetSliceWith[int]("TEST_PARSE_INDEX", nil, WithSliceMode(SliceStrict)); !errors.As(err, &perr) || perr.Key != "TEST_PARSE_INDEX[2]" {
+		t.Errorf("GetSliceWith() error = %v, want TEST_PARSE_INDEX[2] parse error", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PathList is a list of paths separated by os.PathListSeparator, like PATH,
+// e.g. for PLUGIN_PATH or LD_LIBRARY_PATH variables.
+type PathList []string
+
+// GetPathList parses the variable name as a PathList, returning nil if it is not set.
+func GetPathList(name string) PathList {
+	var p PathList
+	key := Key(name)
+	v, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return nil
+	}
+	setValue(key, v, &p)
+	return p
+}
+
+// UnmarshalText splits text using the platform rules of filepath.SplitList,
+// dropping the empty elements.
+func (p *PathList) UnmarshalText(text []byte) error {
+	var out PathList
+	for _, s := range filepath.SplitList(string(text)) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	*p = out
+	return nil
+}
+
+func (p PathList) String() string {
+	return strings.Join(p, string(os.PathListSeparator))
+}
+
+// Unique returns the cleaned paths without duplicates, keeping the first occurrence.
+// Paths are compared case-insensitively on windows.
+func (p PathList) Unique() PathList {
+	var out PathList
+	seen := make(map[string]bool, len(p))
+	for _, s := range p {
+		s = filepath.Clean(s)
+		k := s
+		if runtime.GOOS == "windows" {
+			k = strings.ToLower(k)
+		}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Existing returns the paths that exist on the file system.
+func (p PathList) Existing() PathList {
+	var out PathList
+	for _, s := range p {
+		if _, err := os.Stat(s); err == nil {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPathList(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.Mkdir(a, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sep := string(os.PathListSeparator)
+	setAll(t, map[string]string{"TEST_PATH_LIST": strings.Join([]string{a, "", b, a + string(filepath.Separator), " " + a}, sep)})
+	p := GetPathList("TEST_PATH_LIST")
+	if want := (PathList{a, b, a + string(filepath.Separator), a}); !reflect.DeepEqual(p, want) {
+		t.Errorf("GetPathList() = %v, want %v", p, want)
+	}
+	if want := (PathList{a, b}); !reflect.DeepEqual(p.Unique(), want) {
+		t.Errorf("Unique() = %v, want %v", p.Unique(), want)
+	}
+	if want := (PathList{a}); !reflect.DeepEqual(p.Unique().Existing(), want) {
+		t.Errorf("Existing() = %v, want %v", p.Unique().Existing(), want)
+	}
+	if want := a + sep + b; p.Unique().String() != want {
+		t.Errorf("String() = %s, want %s", p.Unique(), want)
+	}
+
+	var c struct {
+		Plugins PathList `env:"TEST_PATH_LIST"`
+	}
+	if err := Parse(&c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Plugins, p) {
+		t.Errorf("Parse() = %v, want %v", c.Plugins, p)
+	}
+	if GetPathList("TEST_PATH_LIST_UNSET") != nil {
+		t.Error("GetPathList() want nil")
+	}
+}
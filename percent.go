@@ -0,0 +1,87 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Percent is a ratio in [0, 1], parsed from 15% or 0.15.
+type Percent float64
+
+// PercentMode selects how numbers without % suffix are interpreted by ParsePercent.
+type PercentMode int
+
+const (
+	// PercentRatio interprets bare numbers as ratios: 0.15 is 15%.
+	// As values above 1 are rejected, it catches values off by a factor of 100.
+	PercentRatio PercentMode = iota
+	// PercentWhole interprets bare numbers as percentages: 15 is 15%.
+	PercentWhole
+)
+
+// ParsePercent parses s as a percentage, like 15%, or a bare number interpreted
+// according to mode, returning an error if the result is not in [0, 1].
+func ParsePercent(s string, mode PercentMode) (Percent, error) {
+	s = strings.TrimSpace(s)
+	v, isPercent := strings.CutSuffix(s, "%")
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	if isPercent || mode == PercentWhole {
+		f /= 100
+	}
+	if !(f >= 0 && f <= 1) {
+		return 0, fmt.Errorf("percentage %q out of range [0%%, 100%%]", s)
+	}
+	return Percent(f), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using PercentRatio.
+func (p *Percent) UnmarshalText(b []byte) error {
+	v, err := ParsePercent(string(b), PercentRatio)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// GetPercent parses the variable name using mode, returning def if it is not set.
+func GetPercent(name string, def Percent, mode PercentMode) (Percent, error) {
+	key := Key(name)
+	raw, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return def, nil
+	}
+	p, err := ParsePercent(raw, mode)
+	if err != nil {
+		return def, &ParseError{Key: key, Value: raw, Err: err}
+	}
+	return p, nil
+}
+
+// Float64 returns the ratio in [0, 1].
+func (p Percent) Float64() float64 {
+	return float64(p)
+}
+
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p)*100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in   string
+		mode PercentMode
+		want Percent
+		err  bool
+	}{
+		{in: "15%", want: 0.15},
+		{in: " 15 % ", mode: PercentWhole, want: 0.15},
+		{in: "0.15", want: 0.15},
+		{in: "15", mode: PercentWhole, want: 0.15},
+		{in: "15", err: true},
+		{in: "100%", want: 1},
+		{in: "-1%", err: true},
+		{in: "101", mode: PercentWhole, err: true},
+		{in: "half", err: true},
+		{in: "NaN", err: true},
+		{in: "NaN%", err: true},
+		{in: "nan", mode: PercentWhole, err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePercent(tt.in, tt.mode)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParsePercent(%q, %v) = %v, %v, want %v", tt.in, tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+func TestEnvPercent(t *testing.T) {
+	run(t, []TestCase[Percent]{
+		{"TEST", "25%", 0.25, 0.5},
+		{"TEST", "0.1", 0.1, 0.5},
+		{"TEST", "10", 0.5, 0.5},
+	})
+	setAll(t, map[string]string{"TEST": "10"})
+	if got, err := GetPercent("TEST", 0, PercentWhole); err != nil || got != 0.1 || got.String() != "10%" {
+		t.Errorf("GetPercent() = %v, %v, want 10%%", got, err)
+	}
+	if _, err := GetPercent("TEST", 0, PercentRatio); err == nil {
+		t.Error("GetPercent() expected out of range error")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// Prompter asks for the value of a required variable that is not set.
+type Prompter interface {
+	Prompt(v Var) (string, error)
+}
+
+// PrompterFunc is a function implementing Prompter.
+type PrompterFunc func(v Var) (string, error)
+
+func (fn PrompterFunc) Prompt(v Var) (string, error) {
+	return fn(v)
+}
+
+// ErrNoTerminal is returned by Prompters that cannot prompt, e.g. when the input
+// is not a terminal, in which case the variable is reported as missing.
+var ErrNoTerminal = errors.New("env: not a terminal")
+
+var prompter atomic.Pointer[Prompter]
+
+// SetPrompter enables prompting for the required variables that are not set by Parse
+// and Require, instead of failing with ErrMissing. A nil Prompter, the default,
+// disables prompting.
+func SetPrompter(p Prompter) {
+	if p == nil {
+		prompter.Store(nil)
+		return
+	}
+	prompter.Store(&p)
+}
+
+// prompt asks the current Prompter for the value of v.
+// It reports false if prompting is disabled, not possible, or the value is empty.
+func prompt(v Var) (string, bool, error) {
+	p := prompter.Load()
+	if p == nil {
+		return "", false, nil
+	}
+	s, err := (*p).Prompt(v)
+	if errors.Is(err, ErrNoTerminal) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("env: %s: prompt: %w", v.Name, err)
+	}
+	return s, s != "", nil
+}
+
+// Require parses the variable name, failing with ErrMissing if it is not set
+// and cannot be prompted for, see SetPrompter.
+func Require[T Value](name string) (T, error) {
+	var v T
+	key := Key(name)
+	s, ok := lookupOS(key)
+	if !ok {
+		var err error
+		s, ok, err = prompt(Var{Name: name, Type: reflect.TypeOf(v), Required: true})
+		if err != nil {
+			return v, err
+		}
+	}
+	if !ok {
+		missing(key)
+		return v, fmt.Errorf("%w: %s", ErrMissing, key)
+	}
+	if err := parseValue(s, &v); err != nil {
+		return v, &ParseError{Key: key, Value: s, Err: err}
+	}
+	return v, nil
+}
@@ -0,0 +1,12 @@
+module go.linka.cloud/env/prompt
+
+go 1.21
+
+require (
+	go.linka.cloud/env v0.0.0
+	golang.org/x/term v0.15.0
+)
+
+require golang.org/x/sys v0.15.0 // indirect
+
+replace go.linka.cloud/env => ../
@@ -0,0 +1,71 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prompt provides a terminal env.Prompter, kept in its own module so that
+// the env package does not depend on golang.org/x/term.
+//
+//	env.SetPrompter(prompt.TTY())
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+
+	"go.linka.cloud/env"
+)
+
+// TTY returns an env.Prompter reading the values from stdin, writing the prompts to stderr.
+// Secret values are read without echo.
+// Variables are reported as missing when stdin is not a terminal,
+// so that non-interactive runs keep failing.
+func TTY() env.Prompter {
+	return &tty{in: os.Stdin, out: os.Stderr}
+}
+
+type tty struct {
+	mu  sync.Mutex
+	in  *os.File
+	out io.Writer
+	r   *bufio.Reader
+}
+
+func (t *tty) Prompt(v env.Var) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fd := int(t.in.Fd())
+	if !term.IsTerminal(fd) {
+		return "", env.ErrNoTerminal
+	}
+	fmt.Fprintf(t.out, "%s: ", v.Name)
+	if v.Secret {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(t.out)
+		return string(b), err
+	}
+	if t.r == nil {
+		t.r = bufio.NewReader(t.in)
+	}
+	s, err := t.r.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || s == "") {
+		return "", err
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prompt
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"go.linka.cloud/env"
+)
+
+func TestTTYNoTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	p := &tty{in: r, out: io.Discard}
+	if _, err := p.Prompt(env.Var{Name: "TEST_PROMPT"}); !errors.Is(err, env.ErrNoTerminal) {
+		t.Errorf("Prompt() error = %v, want ErrNoTerminal", err)
+	}
+}
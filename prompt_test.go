@@ -0,0 +1,70 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	type config struct {
+		User     string `env:"TEST_PROMPT_USER,required"`
+		Password string `env:"TEST_PROMPT_PASSWORD,required,secret"`
+		Port     int    `env:"TEST_PROMPT_PORT" default:"80"`
+	}
+	setAll(t, map[string]string{"TEST_PROMPT_USER": "", "TEST_PROMPT_PASSWORD": "", "TEST_PROMPT_PORT": ""})
+	for _, k := range []string{"TEST_PROMPT_USER", "TEST_PROMPT_PASSWORD", "TEST_PROMPT_PORT"} {
+		os.Unsetenv(k)
+	}
+	var c config
+	if err := Parse(&c); !errors.Is(err, ErrMissing) {
+		t.Fatalf("Parse() error = %v, want ErrMissing", err)
+	}
+
+	var prompted []string
+	SetPrompter(PrompterFunc(func(v Var) (string, error) {
+		if v.Secret {
+			prompted = append(prompted, v.Name+"(secret)")
+			return "s3cr3t", nil
+		}
+		prompted = append(prompted, v.Name)
+		return map[string]string{"TEST_PROMPT_USER": "admin", "TEST_PROMPT_ID": "42"}[v.Name], nil
+	}))
+	defer SetPrompter(nil)
+	if err := Parse(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.User != "admin" || c.Password != "s3cr3t" || c.Port != 80 {
+		t.Errorf("Parse() = %+v", c)
+	}
+	if want := "TEST_PROMPT_USER,TEST_PROMPT_PASSWORD(secret)"; join(prompted, ",") != want {
+		t.Errorf("prompted = %v, want %v", prompted, want)
+	}
+
+	if v, err := Require[int]("TEST_PROMPT_ID"); err != nil || v != 42 {
+		t.Errorf("Require() = %v, %v, want 42", v, err)
+	}
+	if _, err := Require[int]("TEST_PROMPT_EMPTY"); !errors.Is(err, ErrMissing) {
+		t.Errorf("Require() error = %v, want ErrMissing", err)
+	}
+	SetPrompter(PrompterFunc(func(v Var) (string, error) {
+		return "", ErrNoTerminal
+	}))
+	if _, err := Require[int]("TEST_PROMPT_ID"); !errors.Is(err, ErrMissing) {
+		t.Errorf("Require() error = %v, want ErrMissing", err)
+	}
+}
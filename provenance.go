@@ -0,0 +1,121 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Origins of a Provenance.
+const (
+	// OriginEnv is the process environment, when no other origin was recorded.
+	OriginEnv = originEnv
+	// OriginFile is a file loaded into the process environment, e.g. a dotenv file.
+	OriginFile = "file"
+	// OriginSource is a Source, e.g. a secret store.
+	OriginSource = "source"
+	// OriginDefault is the default of a schema variable.
+	OriginDefault = originDefault
+	// OriginUnset means the variable is not set.
+	OriginUnset = originUnset
+)
+
+// Provenance describes where the value of a variable was loaded from, as recorded
+// by the dotenv loaders and Cache, see Record and Explain.
+type Provenance struct {
+	Key    string
+	Origin string
+	// File and Line locate the value for the OriginFile origin.
+	File string
+	Line int
+	// Source names the Source the value was looked up from, using its String method
+	// if it implements fmt.Stringer, or its type otherwise.
+	Source string
+	// Refreshed is when the value was last loaded or refreshed.
+	Refreshed time.Time
+}
+
+func (p Provenance) String() string {
+	s := p.Key + ": " + p.Origin
+	switch {
+	case p.File != "":
+		s += fmt.Sprintf(" %s:%d", p.File, p.Line)
+	case p.Source != "":
+		s += " " + p.Source
+	}
+	if !p.Refreshed.IsZero() {
+		s += ", refreshed " + p.Refreshed.Format(time.RFC3339)
+	}
+	return s
+}
+
+type provenance struct {
+	p Provenance
+	// hash is the hash of the recorded value, to detect values that were changed since
+	hash uint64
+}
+
+var provenances sync.Map // map[string]provenance
+
+func hashValue(v string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	return h.Sum64()
+}
+
+// Record records the provenance of value, set for the variable p.Key by a loader,
+// e.g. a dotenv file, or returned by a Source. It is reported by Explain
+// as long as the value is not changed.
+func Record(p Provenance, value string) {
+	provenances.Store(p.Key, provenance{p: p, hash: hashValue(value)})
+}
+
+// Explain returns the provenance of the variable name, after applying the KeyMapper.
+// Values set in the process environment are reported with the recorded provenance
+// if they were not changed since, or as OriginEnv otherwise.
+// When the variable is not set in the process environment, the last value recorded
+// from a Source is reported.
+func Explain(name string) Provenance {
+	key := Key(name)
+	a := Default()
+	v, ok := a.LookupEnv(key)
+	if !ok && CaseInsensitive() {
+		v, ok = lookupFold(a.Environ(), key)
+	}
+	r, recorded := provenances.Load(key)
+	switch {
+	case ok && recorded && r.(provenance).p.Origin != OriginSource && r.(provenance).hash == hashValue(v):
+		return r.(provenance).p
+	case ok:
+		return Provenance{Key: key, Origin: OriginEnv}
+	case recorded && r.(provenance).p.Origin == OriginSource:
+		return r.(provenance).p
+	default:
+		return Provenance{Key: key, Origin: OriginUnset}
+	}
+}
+
+// Explain is like the package-level Explain but reports the default of the
+// schema variables that are not set.
+func (s *Schema) Explain(name string) Provenance {
+	p := Explain(name)
+	if v, ok := s.Lookup(name); ok && v.HasDefault && p.Origin == OriginUnset {
+		p.Origin = OriginDefault
+	}
+	return p
+}
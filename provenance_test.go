@@ -0,0 +1,61 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	setAll(t, map[string]string{"TEST_EXPLAIN_FILE": "a"})
+	Record(Provenance{Key: "TEST_EXPLAIN_FILE", Origin: OriginFile, File: ".env", Line: 3}, "a")
+	if got, want := Explain("TEST_EXPLAIN_FILE").String(), "TEST_EXPLAIN_FILE: file .env:3"; got != want {
+		t.Errorf("Explain() = %s, want %s", got, want)
+	}
+	if err := Set("TEST_EXPLAIN_FILE", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Explain("TEST_EXPLAIN_FILE"); got.Origin != OriginEnv {
+		t.Errorf("Explain() = %v, want env once changed", got)
+	}
+	if got := Explain("TEST_EXPLAIN_UNSET"); got.Origin != OriginUnset {
+		t.Errorf("Explain() = %v, want unset", got)
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCache(Resilient(SourceFunc(func(ctx context.Context, key string) (string, bool, error) {
+		return "remote", true, nil
+	})), time.Minute)
+	c.now = func() time.Time { return now }
+	if _, _, err := c.Lookup(context.Background(), "TEST_EXPLAIN_REMOTE"); err != nil {
+		t.Fatal(err)
+	}
+	got := Explain("TEST_EXPLAIN_REMOTE")
+	if got.Origin != OriginSource || !got.Refreshed.Equal(now) || got.Source != "resilient(env.SourceFunc)" {
+		t.Errorf("Explain() = %v, want source refreshed at %v", got, now)
+	}
+
+	s, err := NewSchema(struct {
+		Port int `env:"TEST_EXPLAIN_PORT" default:"80"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Explain("TEST_EXPLAIN_PORT"); got.Origin != OriginDefault {
+		t.Errorf("Schema.Explain() = %v, want default", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Proxy is the proxy configuration read from the HTTP_PROXY, HTTPS_PROXY, ALL_PROXY
+// and NO_PROXY variables, see ProxyConfig.
+type Proxy struct {
+	// HTTP and HTTPS are the proxies of the http and https requests,
+	// nil if requests must not be proxied.
+	HTTP    *url.URL
+	HTTPS   *url.URL
+	NoProxy NoProxy
+}
+
+// proxyVar looks up the upper case variable name, then the lower case one.
+func proxyVar(name string) (string, string, bool) {
+	for _, k := range []string{name, strings.ToLower(name)} {
+		if v, ok := lookupOS(k); ok && v != "" {
+			return k, v, true
+		}
+	}
+	return "", "", false
+}
+
+// ProxyConfig reads the proxy variables, either in upper or lower case.
+// ALL_PROXY is used for both schemes when HTTP_PROXY or HTTPS_PROXY is not set,
+// and proxy URLs without scheme default to http.
+func ProxyConfig() (Proxy, error) {
+	var p Proxy
+	all, err := proxyURL("ALL_PROXY")
+	if err != nil {
+		return p, err
+	}
+	if p.HTTP, err = proxyURL("HTTP_PROXY"); err != nil {
+		return p, err
+	}
+	if p.HTTPS, err = proxyURL("HTTPS_PROXY"); err != nil {
+		return p, err
+	}
+	if p.HTTP == nil {
+		p.HTTP = all
+	}
+	if p.HTTPS == nil {
+		p.HTTPS = all
+	}
+	if _, v, ok := proxyVar("NO_PROXY"); ok {
+		p.NoProxy = ParseNoProxy(v)
+	}
+	return p, nil
+}
+
+func proxyURL(name string) (*url.URL, error) {
+	k, v, ok := proxyVar(name)
+	if !ok {
+		return nil, nil
+	}
+	raw := v
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err == nil && u.Host == "" {
+		err = errors.New("missing host")
+	}
+	if err != nil {
+		return nil, &ParseError{Key: k, Value: v, Err: err}
+	}
+	return u, nil
+}
+
+// ProxyFor returns the proxy to use for u, and whether the request must be proxied.
+// Requests to localhost and loopback addresses are never proxied.
+func (p Proxy) ProxyFor(u *url.URL) (*url.URL, bool) {
+	var proxy *url.URL
+	switch u.Scheme {
+	case "https", "wss":
+		proxy = p.HTTPS
+	case "http", "ws":
+		proxy = p.HTTP
+	}
+	if proxy == nil {
+		return nil, false
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https", "wss":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+	if host == "localhost" {
+		return nil, false
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && ip.IsLoopback() {
+		return nil, false
+	}
+	if p.NoProxy.Match(host, port) {
+		return nil, false
+	}
+	return proxy, true
+}
+
+// ProxyFor reads the proxy variables and returns the proxy to use for u,
+// and whether the request must be proxied. Invalid proxy URLs disable proxying.
+func ProxyFor(u *url.URL) (*url.URL, bool) {
+	p, err := ProxyConfig()
+	if err != nil {
+		return nil, false
+	}
+	return p.ProxyFor(u)
+}
+
+// NoProxy is a parsed NO_PROXY list, see ParseNoProxy.
+type NoProxy []noProxyRule
+
+type noProxyRule struct {
+	all    bool
+	prefix netip.Prefix
+	domain string
+	// exact matches the domain itself, rules starting with a dot only match subdomains
+	exact bool
+	port  string
+}
+
+// ParseNoProxy parses the comma separated NO_PROXY list of:
+//   - "*", matching all hosts
+//   - IP addresses and CIDR ranges, e.g. 10.0.0.0/8
+//   - domains, e.g. example.com matching example.com and its subdomains,
+//     .example.com and *.example.com only matching the subdomains
+//
+// optionally followed by a port, e.g. example.com:8080 or [::1]:8080.
+// Invalid entries are ignored.
+func ParseNoProxy(s string) NoProxy {
+	var rules NoProxy
+	for _, e := range strings.Split(s, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if e == "*" {
+			rules = append(rules, noProxyRule{all: true})
+			continue
+		}
+		if p, err := netip.ParsePrefix(e); err == nil {
+			rules = append(rules, noProxyRule{prefix: p.Masked()})
+			continue
+		}
+		var r noProxyRule
+		if h, port, err := net.SplitHostPort(e); err == nil {
+			e, r.port = h, port
+		}
+		if ip, err := netip.ParseAddr(strings.Trim(e, "[]")); err == nil {
+			r.prefix = netip.PrefixFrom(ip, ip.BitLen())
+			rules = append(rules, r)
+			continue
+		}
+		switch {
+		case strings.HasPrefix(e, "*."):
+			r.domain = e[1:]
+		case strings.HasPrefix(e, "."):
+			r.domain = e
+		default:
+			r.domain, r.exact = "."+e, true
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Match reports whether the host and port, which may be empty, match one of the rules.
+func (n NoProxy) Match(host, port string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip, ipErr := netip.ParseAddr(host)
+	for _, r := range n {
+		if r.all {
+			return true
+		}
+		if r.port != "" && r.port != port {
+			continue
+		}
+		if r.prefix.IsValid() {
+			if ipErr == nil && r.prefix.Contains(ip.Unmap()) {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(host, r.domain) || (r.exact && host == r.domain[1:]) {
+			return true
+		}
+	}
+	return false
+}
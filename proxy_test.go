@@ -0,0 +1,92 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestNoProxy(t *testing.T) {
+	n := ParseNoProxy("example.com, .internal,*.svc.local, 10.0.0.0/8,192.168.1.1, [::1]:8080, api.io:8443, invalid/x")
+	tests := []struct {
+		host, port string
+		want       bool
+	}{
+		{"example.com", "443", true},
+		{"www.example.com", "80", true},
+		{"notexample.com", "80", false},
+		{"internal", "80", false},
+		{"db.internal", "80", true},
+		{"a.svc.local", "80", true},
+		{"svc.local", "80", false},
+		{"10.1.2.3", "80", true},
+		{"11.1.2.3", "80", false},
+		{"192.168.1.1", "80", true},
+		{"::1", "8080", true},
+		{"::1", "80", false},
+		{"api.io", "8443", true},
+		{"api.io", "443", false},
+		{"EXAMPLE.COM.", "443", true},
+	}
+	for _, tt := range tests {
+		if got := n.Match(tt.host, tt.port); got != tt.want {
+			t.Errorf("Match(%s, %s) = %v, want %v", tt.host, tt.port, got, tt.want)
+		}
+	}
+	if !ParseNoProxy("*").Match("anything", "") {
+		t.Error("* should match all hosts")
+	}
+}
+
+func TestProxyFor(t *testing.T) {
+	for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "all_proxy", "no_proxy"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	t.Setenv("http_proxy", "proxy:3128")
+	t.Setenv("ALL_PROXY", "socks5://socks:1080")
+	t.Setenv("NO_PROXY", ".internal,10.0.0.0/8")
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com/path", "http://proxy:3128"},
+		{"https://example.com", "socks5://socks:1080"},
+		{"https://db.internal", ""},
+		{"http://10.0.0.1:8080", ""},
+		{"http://localhost:8080", ""},
+		{"http://127.0.0.1", ""},
+		{"ftp://example.com", ""},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if p, ok := ProxyFor(u); ok {
+			got = p.String()
+		}
+		if got != tt.want {
+			t.Errorf("ProxyFor(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+	t.Setenv("HTTPS_PROXY", "http://%zz")
+	if _, err := ProxyConfig(); err == nil {
+		t.Error("ProxyConfig() expected error")
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UnitFamily is a set of units measuring the same dimension, each defined by its
+// factor relative to the family's base unit, e.g. bytes with KiB = 1024.
+type UnitFamily struct {
+	name string
+	base string
+
+	mu    sync.RWMutex
+	units map[string]float64
+}
+
+// NewUnitFamily returns a UnitFamily whose base unit has factor 1.
+func NewUnitFamily(name, base string, units map[string]float64) *UnitFamily {
+	f := &UnitFamily{name: name, base: base, units: map[string]float64{base: 1}}
+	for u, v := range units {
+		f.units[u] = v
+	}
+	return f
+}
+
+// Register adds or replaces unit, whose value is factor times the base unit.
+func (f *UnitFamily) Register(unit string, factor float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.units[unit] = factor
+}
+
+// Name returns the family name.
+func (f *UnitFamily) Name() string {
+	return f.name
+}
+
+// Base returns the family base unit.
+func (f *UnitFamily) Base() string {
+	return f.base
+}
+
+// Units returns the sorted family units.
+func (f *UnitFamily) Units() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	units := make([]string, 0, len(f.units))
+	for u := range f.units {
+		units = append(units, u)
+	}
+	sort.Strings(units)
+	return units
+}
+
+func (f *UnitFamily) factor(unit string) (float64, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	v, ok := f.units[unit]
+	return v, ok
+}
+
+var (
+	// Bytes is the UnitFamily of data sizes, in both SI (kB, MB…) and IEC (KiB, MiB…) units.
+	Bytes = NewUnitFamily("bytes", "B", map[string]float64{
+		"kB": 1e3, "MB": 1e6, "GB": 1e9, "TB": 1e12, "PB": 1e15,
+		"KiB": 1 << 10, "MiB": 1 << 20, "GiB": 1 << 30, "TiB": 1 << 40, "PiB": 1 << 50,
+		// Kubernetes style
+		"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15,
+		"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50,
+	})
+	// Seconds is the UnitFamily of durations.
+	Seconds = NewUnitFamily("seconds", "s", map[string]float64{
+		"ns": 1e-9, "us": 1e-6, "µs": 1e-6, "ms": 1e-3, "m": 60, "min": 60, "h": 3600, "d": 86400,
+	})
+)
+
+// Quantity is a value expressed in a unit of a UnitFamily, e.g. 512MiB.
+type Quantity struct {
+	Value  float64
+	Unit   string
+	Family *UnitFamily
+}
+
+// ParseQuantity parses a number followed by a unit of family, e.g. 512MiB or 1.5 GB.
+// Numbers without unit are expressed in the family base unit.
+func ParseQuantity(s string, family *UnitFamily) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+')
+	})
+	num, unit := s, family.base
+	if i >= 0 {
+		num, unit = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i:])
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return Quantity{}, fmt.Errorf("invalid quantity %q", s)
+	}
+	if _, ok := family.factor(unit); !ok {
+		return Quantity{}, fmt.Errorf("invalid quantity %q: unknown %s unit %q", s, family.name, unit)
+	}
+	return Quantity{Value: v, Unit: unit, Family: family}, nil
+}
+
+// Base returns the quantity expressed in the family base unit, e.g. 1KiB is 1024 bytes.
+func (q Quantity) Base() float64 {
+	if q.Family == nil {
+		return q.Value
+	}
+	f, _ := q.Family.factor(q.Unit)
+	return q.Value * f
+}
+
+// In returns the quantity expressed in unit, which must belong to the same family.
+func (q Quantity) In(unit string) (float64, error) {
+	if q.Family == nil {
+		return 0, fmt.Errorf("quantity %v has no unit family", q)
+	}
+	f, ok := q.Family.factor(unit)
+	if !ok {
+		return 0, fmt.Errorf("unknown %s unit %q", q.Family.name, unit)
+	}
+	return q.Base() / f, nil
+}
+
+func (q Quantity) String() string {
+	return strconv.FormatFloat(q.Value, 'f', -1, 64) + q.Unit
+}
+
+// GetQuantity parses the variable name as a Quantity of family, returning def if it is not set.
+func GetQuantity(name string, family *UnitFamily, def Quantity) (Quantity, error) {
+	key := Key(name)
+	raw, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return def, nil
+	}
+	q, err := ParseQuantity(raw, family)
+	if err != nil {
+		return def, &ParseError{Key: key, Value: raw, Err: err}
+	}
+	return q, nil
+}
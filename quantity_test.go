@@ -0,0 +1,65 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		fam  *UnitFamily
+		base float64
+		err  bool
+	}{
+		{in: "512MiB", fam: Bytes, base: 512 << 20},
+		{in: "1.5 GB", fam: Bytes, base: 1.5e9},
+		{in: "2Gi", fam: Bytes, base: 2 << 30},
+		{in: "1024", fam: Bytes, base: 1024},
+		{in: "1.5h", fam: Seconds, base: 5400},
+		{in: "250ms", fam: Seconds, base: 0.25},
+		{in: "10 parsecs", fam: Bytes, err: true},
+		{in: "MiB", fam: Bytes, err: true},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuantity(tt.in, tt.fam)
+		if (err != nil) != tt.err || (!tt.err && q.Base() != tt.base) {
+			t.Errorf("ParseQuantity(%q) = %v (%v), %v, want %v", tt.in, q, q.Base(), err, tt.base)
+		}
+	}
+	if q, _ := ParseQuantity("2GiB", Bytes); q.String() != "2GiB" {
+		t.Errorf("String() = %q", q)
+	}
+}
+
+func TestQuantityCustomFamily(t *testing.T) {
+	rps := NewUnitFamily("rate", "rps", map[string]float64{"rpm": 1.0 / 60})
+	rps.Register("rph", 1.0/3600)
+	setAll(t, map[string]string{"TEST_QUANTITY": "7200 rph"})
+	q, err := GetQuantity("TEST_QUANTITY", rps, Quantity{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := q.In("rpm"); err != nil || v != 120 {
+		t.Errorf("In(rpm) = %v, %v, want 120", v, err)
+	}
+	if _, err := q.In("MiB"); err == nil {
+		t.Error("In(MiB) expected error")
+	}
+	if join(rps.Units(), ",") != "rph,rpm,rps" {
+		t.Errorf("Units() = %v", rps.Units())
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a number of events per period, parsed from N/interval expressions
+// like 100/s, 5/m or 10/500ms.
+type Rate struct {
+	Count  int
+	Period time.Duration
+}
+
+var rateUnits = map[string]time.Duration{
+	"ns":  time.Nanosecond,
+	"us":  time.Microsecond,
+	"µs":  time.Microsecond,
+	"ms":  time.Millisecond,
+	"s":   time.Second,
+	"m":   time.Minute,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   24 * time.Hour,
+}
+
+// ParseRate parses a N/interval expression, the interval being either a unit
+// (ns, us, ms, s, m or min, h, d) or a duration like 500ms.
+func ParseRate(s string) (Rate, error) {
+	n, p, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate %q: expected N/interval", s)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil {
+		return Rate{}, fmt.Errorf("invalid rate %q: %w", s, err)
+	}
+	if count < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: negative count", s)
+	}
+	p = strings.TrimSpace(p)
+	period, ok := rateUnits[p]
+	if !ok {
+		if period, err = time.ParseDuration(p); err != nil {
+			return Rate{}, fmt.Errorf("invalid rate %q: %w", s, err)
+		}
+	}
+	if period <= 0 {
+		return Rate{}, errors.New("invalid rate: period must be positive")
+	}
+	return Rate{Count: count, Period: period}, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Rate) UnmarshalText(b []byte) error {
+	v, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// PerSecond returns the number of events per second,
+// e.g. to be used as a golang.org/x/time/rate.Limit.
+func (r Rate) PerSecond() float64 {
+	if r.Period <= 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Period.Seconds()
+}
+
+// Every returns the interval between two events, or zero if the Count is zero.
+func (r Rate) Every() time.Duration {
+	if r.Count <= 0 {
+		return 0
+	}
+	return r.Period / time.Duration(r.Count)
+}
+
+func (r Rate) String() string {
+	switch r.Period {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Count)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Count)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Count)
+	}
+	return fmt.Sprintf("%d/%v", r.Count, r.Period)
+}
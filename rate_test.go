@@ -0,0 +1,54 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rate
+		err  bool
+	}{
+		{in: "100/s", want: Rate{100, time.Second}},
+		{in: " 5 / m ", want: Rate{5, time.Minute}},
+		{in: "10/500ms", want: Rate{10, 500 * time.Millisecond}},
+		{in: "1/d", want: Rate{1, 24 * time.Hour}},
+		{in: "100", err: true},
+		{in: "-1/s", err: true},
+		{in: "1/0s", err: true},
+		{in: "1/fortnight", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseRate(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	r := Rate{10, 500 * time.Millisecond}
+	if r.PerSecond() != 20 || r.Every() != 50*time.Millisecond || r.String() != "10/500ms" {
+		t.Errorf("Rate = %v, %v/s, every %v", r, r.PerSecond(), r.Every())
+	}
+}
+
+func TestEnvRate(t *testing.T) {
+	run(t, []TestCase[Rate]{
+		{"TEST", "100/s", Rate{100, time.Second}, Rate{1, time.Minute}},
+		{"TEST", "5/m", Rate{5, time.Minute}, Rate{1, time.Second}},
+	})
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a Resilient source while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("env: circuit breaker open")
+
+// FailureMode selects how a Resilient source handles lookups failing after all the retries.
+type FailureMode int
+
+const (
+	// FailOnError returns the lookup error.
+	FailOnError FailureMode = iota
+	// UseCached returns the last value successfully looked up for the key,
+	// or the lookup error if there is none.
+	UseCached
+	// UseDefault reports the variable as not set, so that the getters fall back to their default.
+	UseDefault
+)
+
+// RetryPolicy configures the retries of a Resilient source.
+type RetryPolicy struct {
+	// Attempts is the maximum number of lookups, including the first one. Zero means one.
+	Attempts int
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries. Zero means no limit.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the delay after each retry. Zero means 2.
+	Multiplier float64
+	// Jitter randomizes each delay by up to the given fraction, e.g. 0.2 for ±20%,
+	// so that many processes do not retry in lockstep.
+	Jitter float64
+}
+
+func (p RetryPolicy) delay(retry int) time.Duration {
+	m := p.Multiplier
+	if m == 0 {
+		m = 2
+	}
+	d := float64(p.Backoff)
+	for i := 0; i < retry; i++ {
+		d *= m
+		if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// ResilientOption configures a Resilient source.
+type ResilientOption func(r *resilient)
+
+// WithRetry retries the failed lookups according to p.
+func WithRetry(p RetryPolicy) ResilientOption {
+	return func(r *resilient) {
+		r.retry = p
+	}
+}
+
+// WithFailureMode sets how lookups failing after all the retries are handled.
+// The default is FailOnError.
+func WithFailureMode(m FailureMode) ResilientOption {
+	return func(r *resilient) {
+		r.mode = m
+	}
+}
+
+// WithCircuitBreaker stops calling the source for cooldown after threshold consecutive
+// failed lookups, failing them with ErrCircuitOpen, which is then handled according
+// to the FailureMode. A single lookup is let through once the cooldown elapsed.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ResilientOption {
+	return func(r *resilient) {
+		r.threshold = threshold
+		r.cooldown = cooldown
+	}
+}
+
+// Resilient wraps s, typically a remote source, with retries, circuit breaking
+// and a FailureMode, so that transient outages do not fail every lookup.
+func Resilient(s Source, opts ...ResilientOption) Source {
+	r := &resilient{src: s, cache: make(map[string]cached)}
+	for _, fn := range opts {
+		fn(r)
+	}
+	return r
+}
+
+type cached struct {
+	value string
+	ok    bool
+}
+
+type resilient struct {
+	src       Source
+	retry     RetryPolicy
+	mode      FailureMode
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	cache    map[string]cached
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func (r *resilient) time() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func (r *resilient) String() string {
+	return "resilient(" + sourceName(r.src) + ")"
+}
+
+func (r *resilient) Lookup(ctx context.Context, key string) (string, bool, error) {
+	v, ok, err := r.lookup(ctx, key)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.cache[key] = cached{value: v, ok: ok}
+		return v, ok, nil
+	}
+	// the caller gave up, do not hide it
+	if ctx.Err() != nil {
+		return "", false, err
+	}
+	switch r.mode {
+	case UseCached:
+		if c, found := r.cache[key]; found {
+			return c.value, c.ok, nil
+		}
+	case UseDefault:
+		return "", false, nil
+	}
+	return "", false, err
+}
+
+func (r *resilient) lookup(ctx context.Context, key string) (string, bool, error) {
+	attempts := r.retry.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			t := time.NewTimer(r.retry.delay(i - 1))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return "", false, ctx.Err()
+			case <-t.C:
+			}
+		}
+		if !r.allow() {
+			return "", false, ErrCircuitOpen
+		}
+		var v string
+		var ok bool
+		v, ok, err = r.src.Lookup(ctx, key)
+		r.record(err)
+		if err == nil {
+			return v, ok, nil
+		}
+		if ctx.Err() != nil {
+			return "", false, err
+		}
+	}
+	return "", false, err
+}
+
+// allow reports whether the circuit breaker lets a lookup through.
+func (r *resilient) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.threshold <= 0 || r.failures < r.threshold {
+		return true
+	}
+	if r.time().Sub(r.openedAt) < r.cooldown {
+		return false
+	}
+	// half-open: let this lookup through and re-open the circuit if it fails
+	r.openedAt = r.time()
+	return true
+}
+
+func (r *resilient) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.failures = 0
+		return
+	}
+	r.failures++
+	if r.threshold > 0 && r.failures == r.threshold {
+		r.openedAt = r.time()
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type flakySource struct {
+	calls int
+	fail  func(call int) bool
+}
+
+func (s *flakySource) Lookup(_ context.Context, key string) (string, bool, error) {
+	s.calls++
+	if s.fail(s.calls) {
+		return "", false, errors.New("unavailable")
+	}
+	return "42", true, nil
+}
+
+func TestResilientRetry(t *testing.T) {
+	ctx := context.Background()
+	src := &flakySource{fail: func(call int) bool { return call < 3 }}
+	r := Resilient(src, WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}))
+	if v, ok, err := r.Lookup(ctx, "KEY"); err != nil || !ok || v != "42" || src.calls != 3 {
+		t.Errorf("Lookup() = %q, %v, %v after %d calls", v, ok, err, src.calls)
+	}
+	src = &flakySource{fail: func(call int) bool { return true }}
+	r = Resilient(src, WithRetry(RetryPolicy{Attempts: 2}))
+	if _, _, err := r.Lookup(ctx, "KEY"); err == nil || src.calls != 2 {
+		t.Errorf("Lookup() error = %v after %d calls", err, src.calls)
+	}
+}
+
+func TestResilientFailureModes(t *testing.T) {
+	ctx := context.Background()
+	src := &flakySource{fail: func(call int) bool { return call > 1 }}
+	cached := Resilient(src, WithFailureMode(UseCached))
+	if _, _, err := cached.Lookup(ctx, "KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := cached.Lookup(ctx, "KEY"); err != nil || !ok || v != "42" {
+		t.Errorf("UseCached Lookup() = %q, %v, %v", v, ok, err)
+	}
+	if _, _, err := cached.Lookup(ctx, "OTHER"); err == nil {
+		t.Error("UseCached Lookup() expected error without cached value")
+	}
+	def := Resilient(src, WithFailureMode(UseDefault))
+	if got, err := GetDefaultCtx(ctx, "KEY", 1, def); err != nil || got != 1 {
+		t.Errorf("UseDefault GetDefaultCtx() = %v, %v, want 1", got, err)
+	}
+}
+
+func TestResilientCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	src := &flakySource{fail: func(call int) bool { return call <= 3 }}
+	r := Resilient(src, WithCircuitBreaker(2, time.Minute)).(*resilient)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.Lookup(ctx, "KEY"); err == nil {
+			t.Fatal("Lookup() expected error")
+		}
+	}
+	if _, _, err := r.Lookup(ctx, "KEY"); !errors.Is(err, ErrCircuitOpen) || src.calls != 2 {
+		t.Errorf("Lookup() error = %v after %d calls, want circuit open", err, src.calls)
+	}
+	now = now.Add(time.Minute)
+	if _, _, err := r.Lookup(ctx, "KEY"); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("half-open Lookup() error = %v, want source error", err)
+	}
+	if _, _, err := r.Lookup(ctx, "KEY"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Lookup() error = %v, want circuit open", err)
+	}
+	now = now.Add(time.Minute)
+	if v, _, err := r.Lookup(ctx, "KEY"); err != nil || v != "42" {
+		t.Errorf("Lookup() = %q, %v after cooldown", v, err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.delay(i); got != w {
+			t.Errorf("delay(%d) = %v, want %v", i, got, w)
+		}
+	}
+}
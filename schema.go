@@ -0,0 +1,219 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Schema describes the variables bound to a configuration struct, as used by Parse.
+type Schema struct {
+	Vars []Var
+
+	typ reflect.Type
+}
+
+// Var describes a variable bound to a struct field.
+type Var struct {
+	// Name is the variable name, including the nested structs prefixes, e.g. "HTTP_PORT".
+	Name string
+	// Field is the field path in the struct, e.g. "HTTP.Port".
+	Field      string
+	Type       reflect.Type
+	Default    string
+	HasDefault bool
+	Required   bool
+	// Secret marks variables whose value must not be displayed, e.g. in logs.
+	Secret bool
+	// Description documents the variable, from the desc struct tag.
+	Description string
+	// RequiredIf is the name of the variable, optionally followed by =value, which makes
+	// this variable required when it is true, or equal to value.
+	RequiredIf string
+	// DefaultFrom is the name of the variable whose value is used as default.
+	DefaultFrom string
+
+	index []int
+	opts  fieldOptions
+}
+
+// NewSchema returns the Schema of the struct, or pointer to struct, v.
+// See Parse for the supported struct tags.
+func NewSchema(v any) (*Schema, error) {
+	return newSchema(v, "")
+}
+
+func newSchema(v any, prefix string) (*Schema, error) {
+	rt := reflect.TypeOf(v)
+	if rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: schema expects a struct, got %T", v)
+	}
+	s := &Schema{typ: rt}
+	var errs Errors
+	s.walk(rt, prefix, "", nil, &errs)
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	for i, v := range s.Vars {
+		s.Vars[i].RequiredIf = s.ref(v, v.RequiredIf)
+		s.Vars[i].DefaultFrom = s.ref(v, v.DefaultFrom)
+	}
+	return s, nil
+}
+
+// ref resolves the name of the variable referenced by v: a variable of the same
+// nested struct if it exists, or the variable name as is.
+func (s *Schema) ref(v Var, name string) string {
+	if name == "" {
+		return ""
+	}
+	n, val, hasVal := strings.Cut(name, "=")
+	if p := strings.TrimSuffix(v.Name, v.opts.name); p != "" {
+		if _, ok := s.Lookup(p + n); ok {
+			n = p + n
+		}
+	}
+	if hasVal {
+		return n + "=" + val
+	}
+	return n
+}
+
+// Lookup returns the variable named name.
+func (s *Schema) Lookup(name string) (Var, bool) {
+	for _, v := range s.Vars {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Var{}, false
+}
+
+const (
+	originEnv     = "env"
+	originDefault = "default"
+	originUnset   = "unset"
+)
+
+// resolve returns the raw value of the variable and where it comes from.
+func (v Var) resolve() (raw string, origin string) {
+	if raw, ok := lookup(v.Name); ok {
+		return raw, originEnv
+	}
+	if v.HasDefault {
+		return v.Default, originDefault
+	}
+	return "", originUnset
+}
+
+func (s *Schema) walk(rt reflect.Type, prefix, path string, index []int, errs *Errors) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+		o, err := parseTag(tag)
+		if err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+		if sf.Type.Kind() == reflect.Struct && !isValue(sf.Type) {
+			p := prefix
+			if o.name != "" {
+				p += o.name + "_"
+			}
+			s.walk(sf.Type, p, path+sf.Name+".", idx, errs)
+			continue
+		}
+		if !ok || o.name == "" {
+			continue
+		}
+		def, hasDef := sf.Tag.Lookup("default")
+		s.Vars = append(s.Vars, Var{
+			Name:        prefix + o.name,
+			Field:       path + sf.Name,
+			Type:        sf.Type,
+			Default:     def,
+			HasDefault:  hasDef,
+			Required:    o.required,
+			Secret:      o.secret,
+			Description: sf.Tag.Get("desc"),
+			RequiredIf:  o.requiredIf,
+			DefaultFrom: o.defaultFrom,
+			index:       idx,
+			opts:        o,
+		})
+	}
+}
+
+type fieldOptions struct {
+	name        string
+	required    bool
+	requiredIf  string
+	defaultFrom string
+	secret      bool
+	slice       sliceOptions
+}
+
+func parseTag(tag string) (fieldOptions, error) {
+	parts := strings.Split(tag, ",")
+	o := fieldOptions{name: strings.TrimSpace(parts[0])}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+		var err error
+		switch k {
+		case "required":
+			o.required = true
+		case "required_if":
+			o.requiredIf = v
+		case "default_from":
+			o.defaultFrom = v
+		case "secret":
+			o.secret = true
+		case "unique":
+			o.slice.unique = true
+		case "minlen":
+			o.slice.minLen, err = strconv.Atoi(v)
+		case "maxlen":
+			o.slice.maxLen, err = strconv.Atoi(v)
+		default:
+			return o, fmt.Errorf("env: %s: unknown tag option %q", o.name, k)
+		}
+		if (k == "required_if" || k == "default_from") && v == "" {
+			err = errors.New("missing variable name")
+		}
+		if err != nil {
+			return o, fmt.Errorf("env: %s: invalid tag option %q: %w", o.name, p, err)
+		}
+	}
+	return o, nil
+}
+
+// isValue reports whether t can be parsed from a single value.
+func isValue(t reflect.Type) bool {
+	return !errors.Is(parseValue("", reflect.New(t).Interface()), errUnsupportedType)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoLimit is returned by CgroupMemoryLimit when no memory limit is set.
+var ErrNoLimit = errors.New("env: no limit set")
+
+// SentinelResolver resolves the sentinel value of the variable key, e.g. "auto",
+// to the actual value.
+type SentinelResolver func(key string) (string, error)
+
+type sentinelKey struct {
+	key   string
+	value string
+}
+
+var (
+	sentinelsMu sync.RWMutex
+	sentinels   = make(map[sentinelKey]SentinelResolver)
+)
+
+// RegisterSentinel registers resolve to compute the value of the variable key
+// when it is set to sentinel, e.g.:
+//
+//	env.RegisterSentinel("WORKERS", "auto", env.NumCPU)
+//	env.RegisterSentinel("MEM_LIMIT", "auto", env.CgroupMemoryLimit)
+//
+// The key is mapped by the KeyMapper set at registration time.
+// Sentinels are matched case-insensitively. If the resolver fails, the error is
+// reported to the OnParseError hook and the raw value is kept.
+// Passing a nil resolver removes the sentinel.
+func RegisterSentinel(key, sentinel string, resolve SentinelResolver) {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+	k := sentinelKey{key: Key(key), value: strings.ToLower(sentinel)}
+	if resolve == nil {
+		delete(sentinels, k)
+		return
+	}
+	sentinels[k] = resolve
+}
+
+// resolveSentinel returns the resolved value of key if v is one of its sentinels.
+func resolveSentinel(key, v string) string {
+	sentinelsMu.RLock()
+	if len(sentinels) == 0 {
+		sentinelsMu.RUnlock()
+		return v
+	}
+	fn, ok := sentinels[sentinelKey{key: key, value: strings.ToLower(strings.TrimSpace(v))}]
+	sentinelsMu.RUnlock()
+	if !ok {
+		return v
+	}
+	r, err := fn(key)
+	if err != nil {
+		parseError(key, v, err)
+		return v
+	}
+	return r
+}
+
+// NumCPU is a SentinelResolver returning the number of logical CPUs usable by the process.
+func NumCPU(string) (string, error) {
+	return strconv.Itoa(runtime.NumCPU()), nil
+}
+
+// CgroupMemoryLimit is a SentinelResolver returning the cgroup memory limit of the process
+// in bytes, supporting both cgroup v2 and v1. It returns ErrNoLimit if no limit is set.
+func CgroupMemoryLimit(string) (string, error) {
+	for _, p := range []string{
+		"/sys/fs/cgroup/memory.max",
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	} {
+		b, err := os.ReadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return parseMemoryLimit(string(b))
+	}
+	return "", ErrNoLimit
+}
+
+func parseMemoryLimit(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "max" {
+		return "", ErrNoLimit
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	// cgroup v1 reports the max int64 rounded to the page size when unlimited
+	if n >= 1<<62 {
+		return "", ErrNoLimit
+	}
+	return s, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestSentinel(t *testing.T) {
+	RegisterSentinel("TEST_WORKERS", "auto", NumCPU)
+	defer RegisterSentinel("TEST_WORKERS", "auto", nil)
+	RegisterSentinel("TEST_LIMIT", "auto", func(string) (string, error) {
+		return "", ErrNoLimit
+	})
+	defer RegisterSentinel("TEST_LIMIT", "auto", nil)
+	var failed []string
+	OnParseError(func(key, raw string, err error) {
+		failed = append(failed, key)
+	})
+	defer OnParseError(nil)
+
+	setAll(t, map[string]string{"TEST_WORKERS": " AUTO ", "TEST_LIMIT": "auto"})
+	if got := GetDefault("TEST_WORKERS", 1); got != runtime.NumCPU() {
+		t.Errorf("GetDefault() = %d, want %d", got, runtime.NumCPU())
+	}
+	if got := GetDefault("TEST_LIMIT", 42); got != 42 {
+		t.Errorf("GetDefault() = %d, want 42", got)
+	}
+	if join(failed, ",") != "TEST_LIMIT,TEST_LIMIT" {
+		t.Errorf("parse errors = %v", failed)
+	}
+	setAll(t, map[string]string{"TEST_WORKERS": "4"})
+	if got := Get[int]("TEST_WORKERS"); got != 4 {
+		t.Errorf("Get() = %d, want 4", got)
+	}
+}
+
+func TestParseMemoryLimit(t *testing.T) {
+	if v, err := parseMemoryLimit("536870912\n"); err != nil || v != "536870912" {
+		t.Errorf("parseMemoryLimit() = %q, %v", v, err)
+	}
+	for _, s := range []string{"max\n", "9223372036854771712"} {
+		if _, err := parseMemoryLimit(s); !errors.Is(err, ErrNoLimit) {
+			t.Errorf("parseMemoryLimit(%q) error = %v, want ErrNoLimit", s, err)
+		}
+	}
+}
+
+func TestSentinelMappedKey(t *testing.T) {
+	SetKeyMapper(UpperSnakeCase)
+	defer SetKeyMapper(nil)
+	RegisterSentinel("test.sentinel.workers", "auto", func(string) (string, error) {
+		return "7", nil
+	})
+	defer RegisterSentinel("test.sentinel.workers", "auto", nil)
+	setAll(t, map[string]string{"TEST_SENTINEL_WORKERS": "auto"})
+	if v := Get[int]("test.sentinel.workers"); v != 7 {
+		t.Errorf("Get() = %v, want 7", v)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Shell is a shell targeted by Completion.
+type Shell int
+
+const (
+	ShellBash Shell = iota
+	ShellZsh
+)
+
+func (s Shell) String() string {
+	switch s {
+	case ShellBash:
+		return "bash"
+	case ShellZsh:
+		return "zsh"
+	default:
+		return fmt.Sprintf("Shell(%d)", int(s))
+	}
+}
+
+// ShellDefaults writes a POSIX shell snippet exporting the defaults of the schema variables,
+// keeping the values already set, e.g. to be sourced from a direnv .envrc:
+//
+//	# listen address
+//	[ -n "${ADDR+set}" ] || ADDR=':8080'; export ADDR
+//
+// Defaults are single quoted, so that they are used verbatim by any POSIX shell.
+// Variables without default are listed as comments.
+func ShellDefaults(w io.Writer, s *Schema) error {
+	bw := bufio.NewWriter(w)
+	for i, v := range s.Vars {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		if v.Description != "" {
+			fmt.Fprintf(bw, "# %s\n", strings.ReplaceAll(v.Description, "\n", "\n# "))
+		}
+		if !v.HasDefault {
+			req := ""
+			if v.Required {
+				req = " (required)"
+			}
+			fmt.Fprintf(bw, "# export %s=%s\n", v.Name, req)
+			continue
+		}
+		fmt.Fprintf(bw, "[ -n \"${%s+set}\" ] || %s=%s; export %s\n", v.Name, v.Name, shellQuote(v.Default), v.Name)
+	}
+	return bw.Flush()
+}
+
+// shellQuote single quotes v for the shell.
+func shellQuote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
+
+var shellIdent = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Completion writes a completion script for the command cmd, completing the schema
+// variables as NAME= arguments, e.g. for commands accepting the variables on their command line.
+// The zsh script displays the variables descriptions.
+func Completion(w io.Writer, s *Schema, shell Shell, cmd string) error {
+	fn := "_" + shellIdent.ReplaceAllString(cmd, "_") + "_env"
+	bw := bufio.NewWriter(w)
+	switch shell {
+	case ShellBash:
+		fmt.Fprintf(bw, "# bash completion for %s environment variables\n", cmd)
+		fmt.Fprintf(bw, "%s() {\n", fn)
+		bw.WriteString("\tlocal cur=${COMP_WORDS[COMP_CWORD]}\n")
+		bw.WriteString("\tlocal vars=\"")
+		for i, v := range s.Vars {
+			if i > 0 {
+				bw.WriteString(" ")
+			}
+			bw.WriteString(v.Name + "=")
+		}
+		bw.WriteString("\"\n")
+		bw.WriteString("\tCOMPREPLY=($(compgen -W \"$vars\" -- \"$cur\"))\n")
+		bw.WriteString("\tcompopt -o nospace 2>/dev/null\n")
+		bw.WriteString("}\n")
+		fmt.Fprintf(bw, "complete -F %s %s\n", fn, cmd)
+	case ShellZsh:
+		fmt.Fprintf(bw, "#compdef %s\n", cmd)
+		fmt.Fprintf(bw, "%s() {\n", fn)
+		bw.WriteString("\tlocal -a vars\n")
+		bw.WriteString("\tvars=(\n")
+		for _, v := range s.Vars {
+			d := strings.Join(strings.Fields(v.Description), " ")
+			fmt.Fprintf(bw, "\t\t'%s:%s'\n", v.Name, strings.ReplaceAll(d, "'", `'\''`))
+		}
+		bw.WriteString("\t)\n")
+		bw.WriteString("\t_describe -t variables 'environment variable' vars -S '='\n")
+		bw.WriteString("}\n")
+		fmt.Fprintf(bw, "compdef %s %s\n", fn, cmd)
+	default:
+		return fmt.Errorf("env: unsupported shell %v", shell)
+	}
+	return bw.Flush()
+}
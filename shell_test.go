@@ -0,0 +1,96 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type shellConfig struct {
+	Addr  string `env:"ADDR" default:":8080" desc:"listen address"`
+	Token string `env:"TOKEN,required,secret" desc:"API token, see 'docs'"`
+	Greet string "env:\"GREET\" default:\"it's \\\"$USER\\\" ${x} `id` \\\\n\""
+}
+
+func TestShellDefaults(t *testing.T) {
+	s, err := NewSchema(shellConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := ShellDefaults(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	want := `# listen address
+[ -n "${ADDR+set}" ] || ADDR=':8080'; export ADDR
+
+# API token, see 'docs'
+# export TOKEN= (required)
+
+[ -n "${GREET+set}" ] || GREET='it'\''s "$USER" ${x} ` + "`id`" + ` \n'; export GREET
+`
+	if buf.String() != want {
+		t.Fatalf("ShellDefaults() =\n%s\nwant\n%s", buf.String(), want)
+	}
+	for _, sh := range []string{"sh", "bash"} {
+		if _, err := exec.LookPath(sh); err != nil {
+			t.Logf("%s not found", sh)
+			continue
+		}
+		if out, err := exec.Command(sh, "-n", "-c", buf.String()).CombinedOutput(); err != nil {
+			t.Fatalf("%s -n: %v: %s", sh, err, out)
+		}
+		cmd := exec.Command(sh, "-c", buf.String()+`printf '%s|%s' "$ADDR" "$GREET"`)
+		cmd.Env = []string{"ADDR=:9090"}
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := ":9090|it's \"$USER\" ${x} `id` \\n"; string(out) != want {
+			t.Errorf("%s: sourced = %s, want %s", sh, out, want)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	s, err := NewSchema(shellConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Completion(&buf, s, ShellBash, "my-app"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`local vars="ADDR= TOKEN= GREET="`, "complete -F _my_app_env my-app\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("bash completion missing %q:\n%s", want, buf.String())
+		}
+	}
+	buf.Reset()
+	if err := Completion(&buf, s, ShellZsh, "my-app"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#compdef my-app\n", `'ADDR:listen address'`, `'TOKEN:API token, see '\''docs'\'''`, "compdef _my_app_env my-app\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("zsh completion missing %q:\n%s", want, buf.String())
+		}
+	}
+	if err := Completion(&buf, s, Shell(42), "my-app"); err == nil {
+		t.Error("Completion() expected error")
+	}
+}
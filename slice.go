@@ -0,0 +1,272 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SliceMode controls how elements that fail to parse are handled by GetSliceWith.
+type SliceMode int
+
+const (
+	// SliceMerge parses each element over the default element at the same index:
+	// an invalid element silently keeps def[i], or the zero value past the end of def.
+	// Empty elements are dropped before the defaults are matched by index.
+	// This is the behavior of GetSliceDefault.
+	SliceMerge SliceMode = iota
+	// SliceZeroFill replaces invalid elements with the zero value.
+	SliceZeroFill
+	// SliceStrict returns a *ParseError for the first invalid element.
+	SliceStrict
+)
+
+func (m SliceMode) String() string {
+	switch m {
+	case SliceMerge:
+		return "merge"
+	case SliceZeroFill:
+		return "zero-fill"
+	case SliceStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("SliceMode(%d)", int(m))
+	}
+}
+
+// SliceOption configures GetSliceWith.
+type SliceOption func(o *sliceOptions)
+
+type sliceOptions struct {
+	mode     SliceMode
+	minLen   int
+	maxLen   int
+	unique   bool
+	validate func(v any) error
+}
+
+// WithSliceMode sets how elements that fail to parse are handled. The default is SliceMerge.
+func WithSliceMode(m SliceMode) SliceOption {
+	return func(o *sliceOptions) {
+		o.mode = m
+	}
+}
+
+// WithMinLen requires the slice to contain at least n elements.
+func WithMinLen(n int) SliceOption {
+	return func(o *sliceOptions) {
+		o.minLen = n
+	}
+}
+
+// WithMaxLen requires the slice to contain at most n elements.
+func WithMaxLen(n int) SliceOption {
+	return func(o *sliceOptions) {
+		o.maxLen = n
+	}
+}
+
+// WithUnique rejects slices containing duplicate elements.
+func WithUnique() SliceOption {
+	return func(o *sliceOptions) {
+		o.unique = true
+	}
+}
+
+// WithValidate calls fn for each parsed element, failing with a *ParseError
+// referencing the element index when it returns an error.
+func WithValidate[T Value](fn func(T) error) SliceOption {
+	return func(o *sliceOptions) {
+		o.validate = func(v any) error {
+			t, ok := v.(T)
+			if !ok {
+				return fmt.Errorf("validate: want %T, got %T", t, v)
+			}
+			return fn(t)
+		}
+	}
+}
+
+// check validates the length and uniqueness of the slice elements,
+// given in their string representation.
+func (o *sliceOptions) check(name string, elems []string) error {
+	if len(elems) < o.minLen {
+		return fmt.Errorf("env: %s: got %d elements, want at least %d", name, len(elems), o.minLen)
+	}
+	if o.maxLen > 0 && len(elems) > o.maxLen {
+		return fmt.Errorf("env: %s: got %d elements, want at most %d", name, len(elems), o.maxLen)
+	}
+	if !o.unique {
+		return nil
+	}
+	seen := make(map[string]int, len(elems))
+	for i, v := range elems {
+		if j, ok := seen[v]; ok {
+			return fmt.Errorf("env: %s: duplicate element %q at index %d and %d", name, v, j, i)
+		}
+		seen[v] = i
+	}
+	return nil
+}
+
+// splitSlice splits a comma separated list, dropping empty elements.
+func splitSlice(v string) []string {
+	return splitList(v, false)
+}
+
+// splitList splits a comma separated list.
+// Unquoted elements are trimmed, and dropped when empty unless keepEmpty is set.
+// Double quoted elements are kept as is, after unescaping \" and \\.
+func splitList(v string, keepEmpty bool) []string {
+	var out []string
+	eachElem(v, func(_ int, elem string, quoted bool) {
+		if quoted || elem != "" || keepEmpty {
+			out = append(out, elem)
+		}
+	})
+	return out
+}
+
+// splitIndexed splits a comma separated list like splitSlice,
+// also returning the position of each element in the list, empty elements included.
+func splitIndexed(v string) ([]string, []int) {
+	var elems []string
+	var idx []int
+	eachElem(v, func(i int, elem string, quoted bool) {
+		if quoted || elem != "" {
+			elems = append(elems, elem)
+			idx = append(idx, i)
+		}
+	})
+	return elems, idx
+}
+
+// eachElem calls fn for every element of the comma separated list v,
+// with its position in the list and whether it was double quoted.
+func eachElem(v string, fn func(i int, elem string, quoted bool)) {
+	for i, n := 0, 0; i <= len(v); n++ {
+		end := strings.IndexByte(v[i:], ',')
+		if end < 0 {
+			end = len(v)
+		} else {
+			end += i
+		}
+		if s := strings.TrimLeft(v[i:], " \t"); strings.HasPrefix(s, `"`) {
+			if elem, m, ok := unquoteElem(s); ok {
+				rest := s[m:]
+				next := strings.IndexByte(rest, ',')
+				if next < 0 {
+					next = len(rest)
+				}
+				if strings.TrimSpace(rest[:next]) == "" {
+					fn(n, elem, true)
+					i = len(v) - len(rest) + next + 1
+					continue
+				}
+			}
+		}
+		fn(n, strings.TrimSpace(v[i:end]), false)
+		i = end + 1
+	}
+}
+
+// unquoteElem unquotes the double quoted element at the start of s,
+// returning the number of bytes consumed.
+func unquoteElem(s string) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\'):
+			b.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			return b.String(), i + 1, true
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// quoteElem double quotes s if it could not be read back as is from a comma separated list.
+func quoteElem(s string) string {
+	if s != "" && !strings.ContainsAny(s, `,"`) && strings.TrimSpace(s) == s {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// GetSliceWith parses the comma separated variable name, returning def if the variable
+// is not set or contains no elements.
+// Length and uniqueness constraints are checked against the returned slice, defaults included.
+func GetSliceWith[T Value](name string, def []T, opts ...SliceOption) ([]T, error) {
+	var o sliceOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	key := Key(name)
+	v, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return def, o.check(key, format(def))
+	}
+	// empty elements keep their position in the error keys,
+	// while defaults are merged by position among the remaining elements
+	s, idx := splitIndexed(v)
+	if len(s) == 0 {
+		return def, o.check(key, format(def))
+	}
+	var out []T
+	for j, v := range s {
+		i := idx[j]
+		var val T
+		if o.mode == SliceMerge && j < len(def) {
+			val = def[j]
+		}
+		elem := fmt.Sprintf("%s[%d]", key, i)
+		if err := parseRaw(v, any(&val)); err != nil {
+			if o.mode == SliceStrict {
+				return nil, &ParseError{Key: elem, Value: v, Err: err}
+			}
+			parseError(elem, v, err)
+		}
+		if o.validate != nil {
+			if err := o.validate(val); err != nil {
+				return nil, &ParseError{Key: elem, Value: v, Err: err}
+			}
+		}
+		out = append(out, val)
+	}
+	if err := o.check(key, format(out)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func format[T any](v []T) []string {
+	s := make([]string, len(v))
+	for i, v := range v {
+		s[i] = fmt.Sprintf("%v", v)
+	}
+	return s
+}
+
+// GetSliceValidated parses the comma separated variable name in SliceStrict mode
+// and calls validate for each element.
+func GetSliceValidated[T Value](name string, validate func(T) error) ([]T, error) {
+	return GetSliceWith[T](name, nil, WithSliceMode(SliceStrict), WithValidate(validate))
+}
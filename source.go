@@ -0,0 +1,180 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Source provides variables values, e.g. from the process environment or a secret store.
+// Lookup receives the variable name after the KeyMapper is applied
+// and must return ok false, without error, when the variable is not defined.
+// Sources implementing fmt.Stringer are reported by name in the provenances
+// and the OnSourceLookup hook, instead of by type.
+type Source interface {
+	Lookup(ctx context.Context, key string) (value string, ok bool, err error)
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc func(ctx context.Context, key string) (string, bool, error)
+
+func (fn SourceFunc) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return fn(ctx, key)
+}
+
+// OS is the Source reading the Default Accessor, the process environment by default.
+var OS Source = osSource{}
+
+type osSource struct{}
+
+func (osSource) String() string {
+	return "os"
+}
+
+func (osSource) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	if skip, _ := ctx.Value(skipTransformsKey{}).(bool); skip {
+		v, ok := lookupRaw(key)
+		if ok {
+			v = resolveSentinel(key, v)
+		}
+		return v, ok, nil
+	}
+	v, ok := lookupOS(key)
+	return v, ok, nil
+}
+
+// Chain returns a Source looking up the variables in each source in order,
+// returning the first value found.
+func Chain(sources ...Source) Source {
+	return chain(sources)
+}
+
+type chain []Source
+
+func (c chain) String() string {
+	names := make([]string, len(c))
+	for i, s := range c {
+		names[i] = sourceName(s)
+	}
+	return "chain(" + strings.Join(names, ", ") + ")"
+}
+
+func (c chain) Lookup(ctx context.Context, key string) (string, bool, error) {
+	for _, s := range c {
+		if err := ctx.Err(); err != nil {
+			return "", false, err
+		}
+		v, ok, err := s.Lookup(ctx, key)
+		if err != nil || ok {
+			return v, ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// sourceName returns the name of s, see Source.
+func sourceName(s Source) string {
+	if n, ok := s.(fmt.Stringer); ok {
+		return n.String()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+type overridesKey struct{}
+
+// ContextWith returns a copy of ctx carrying the values, which GetCtx and GetDefaultCtx
+// read before the sources, e.g. for per-request or per-tenant overrides.
+// The values override those of the parent context, and are transformed and resolved
+// like the values of the process environment, see SetTransforms and RegisterSentinel.
+func ContextWith(ctx context.Context, values map[string]string) context.Context {
+	parent, _ := ctx.Value(overridesKey{}).(map[string]string)
+	m := make(map[string]string, len(parent)+len(values))
+	for k, v := range parent {
+		m[k] = v
+	}
+	for k, v := range values {
+		m[Key(k)] = v
+	}
+	return context.WithValue(ctx, overridesKey{}, m)
+}
+
+// lookupFoldMap returns the value of the key of m matching key case-insensitively,
+// the first one in sorted order if several match.
+func lookupFoldMap(m map[string]string, key string) (string, bool) {
+	var match string
+	found := false
+	for k := range m {
+		if strings.EqualFold(k, key) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	return m[match], found
+}
+
+// lookupCtx looks up name in the ctx overrides, then in sources,
+// or in the process environment if none is given.
+func lookupCtx(ctx context.Context, name string, sources []Source) (string, bool, error) {
+	if len(sources) == 0 {
+		sources = []Source{OS}
+	}
+	key := Key(name)
+	if m, ok := ctx.Value(overridesKey{}).(map[string]string); ok {
+		v, found := m[key]
+		if !found && CaseInsensitive() {
+			v, found = lookupFoldMap(m, key)
+		}
+		if found {
+			looked(key, true)
+			return resolve(key, v), true, nil
+		}
+	}
+	v, ok, err := chain(sources).Lookup(ctx, key)
+	if err != nil {
+		return "", false, fmt.Errorf("env: lookup %s: %w", key, err)
+	}
+	return v, ok, nil
+}
+
+// GetCtx returns the value of the variable name from the ctx overrides, see ContextWith,
+// or from the first of the sources defining it, or from the process environment
+// if no source is given.
+// It returns the zero value if the variable is not set, and an error if the lookup
+// fails, e.g. when ctx is done, or if the value cannot be parsed.
+func GetCtx[T Value](ctx context.Context, name string, sources ...Source) (T, error) {
+	var zero T
+	return GetDefaultCtx(ctx, name, zero, sources...)
+}
+
+// GetDefaultCtx is like GetCtx but returns def if the variable is not set.
+func GetDefaultCtx[T Value](ctx context.Context, name string, def T, sources ...Source) (T, error) {
+	raw, ok, err := lookupCtx(ctx, name, sources)
+	if err != nil {
+		return def, err
+	}
+	if !ok {
+		missing(Key(name))
+		return def, nil
+	}
+	v := def
+	if err := parseValue(raw, any(&v)); err != nil {
+		return def, &ParseError{Key: Key(name), Value: raw, Err: err}
+	}
+	return v, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func mapSource(m map[string]string) Source {
+	return SourceFunc(func(_ context.Context, key string) (string, bool, error) {
+		v, ok := m[key]
+		return v, ok, nil
+	})
+}
+
+func TestGetCtx(t *testing.T) {
+	ctx := context.Background()
+	setAll(t, map[string]string{"TEST_CTX_OS": "1"})
+	if got, err := GetCtx[int](ctx, "TEST_CTX_OS"); err != nil || got != 1 {
+		t.Errorf("GetCtx() = %v, %v, want 1", got, err)
+	}
+	remote := mapSource(map[string]string{"TEST_CTX_OS": "2", "TEST_CTX_REMOTE": "3", "TEST_CTX_BAD": "x"})
+	if got, err := GetCtx[int](ctx, "TEST_CTX_OS", OS, remote); err != nil || got != 1 {
+		t.Errorf("GetCtx(OS, remote) = %v, %v, want 1", got, err)
+	}
+	if got, err := GetCtx[int](ctx, "TEST_CTX_REMOTE", OS, remote); err != nil || got != 3 {
+		t.Errorf("GetCtx(OS, remote) = %v, %v, want 3", got, err)
+	}
+	if got, err := GetDefaultCtx(ctx, "TEST_CTX_MISSING", 4, OS, remote); err != nil || got != 4 {
+		t.Errorf("GetDefaultCtx() = %v, %v, want 4", got, err)
+	}
+	var perr *ParseError
+	if _, err := GetCtx[int](ctx, "TEST_CTX_BAD", remote); !errors.As(err, &perr) {
+		t.Errorf("GetCtx() error = %v, want parse error", err)
+	}
+}
+
+func TestGetCtxCancel(t *testing.T) {
+	blocking := SourceFunc(func(ctx context.Context, key string) (string, bool, error) {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	got, err := GetDefaultCtx(ctx, "TEST_CTX_MISSING", 4, blocking)
+	if !errors.Is(err, context.DeadlineExceeded) || got != 4 {
+		t.Errorf("GetDefaultCtx() = %v, %v, want deadline exceeded", got, err)
+	}
+	if _, err := GetCtx[int](ctx, "TEST_CTX_MISSING"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetCtx() error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestContextWith(t *testing.T) {
+	setAll(t, map[string]string{"TEST_CTX_OS": "1", "TEST_CTX_OTHER": "2"})
+	tenant := ContextWith(context.Background(), map[string]string{"TEST_CTX_OS": "10", "TEST_CTX_ONLY": "11"})
+	request := ContextWith(tenant, map[string]string{"TEST_CTX_ONLY": "12"})
+	tests := []struct {
+		ctx  context.Context
+		name string
+		want int
+	}{
+		{tenant, "TEST_CTX_OS", 10},
+		{tenant, "TEST_CTX_OTHER", 2},
+		{tenant, "TEST_CTX_ONLY", 11},
+		{request, "TEST_CTX_OS", 10},
+		{request, "TEST_CTX_ONLY", 12},
+		{context.Background(), "TEST_CTX_OS", 1},
+	}
+	for _, tt := range tests {
+		if got, err := GetCtx[int](tt.ctx, tt.name); err != nil || got != tt.want {
+			t.Errorf("GetCtx(%s) = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if got, err := GetCtx[int](request, "TEST_CTX_OS", mapSource(map[string]string{"TEST_CTX_OS": "3"})); err != nil || got != 10 {
+		t.Errorf("GetCtx(remote) = %v, %v, want the context override", got, err)
+	}
+	prev := CaseInsensitive()
+	SetCaseInsensitive(true)
+	defer SetCaseInsensitive(prev)
+	if got, err := GetCtx[int](request, "test_ctx_only"); err != nil || got != 12 {
+		t.Errorf("GetCtx() = %v, %v, want case-insensitive match", got, err)
+	}
+	folded := ContextWith(context.Background(), map[string]string{"test_ctx_fold": "1", "Test_Ctx_Fold": "2", "TEST_CTX_FOLd": "3"})
+	for i := 0; i < 10; i++ {
+		if got, err := GetCtx[int](folded, "TEST_CTX_FOLD"); err != nil || got != 3 {
+			t.Fatalf("GetCtx() = %v, %v, want the first match in sorted order", got, err)
+		}
+	}
+}
+
+func TestContextWithResolve(t *testing.T) {
+	var looked []string
+	OnLookup(func(key string, found bool) {
+		if found {
+			looked = append(looked, key)
+		}
+	})
+	defer OnLookup(nil)
+	SetTransforms(TrimBOM)
+	defer SetTransforms()
+	ctx := ContextWith(context.Background(), map[string]string{"TEST_CTX_BOM": "\uFEFF42"})
+	if got, err := GetCtx[int](ctx, "TEST_CTX_BOM"); err != nil || got != 42 {
+		t.Errorf("GetCtx() = %v, %v, want the transformed override", got, err)
+	}
+	if want := "TEST_CTX_BOM"; join(looked, ",") != want {
+		t.Errorf("lookups = %v, want %v", looked, want)
+	}
+}
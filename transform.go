@@ -0,0 +1,98 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// TransformFunc rewrites the raw value of the variable key before it is parsed,
+// e.g. to decode legacy formats without changing the call sites.
+type TransformFunc func(key, value string) (string, error)
+
+// TrimBOM is a TransformFunc removing the UTF-8 byte order mark at the start of values,
+// as sometimes left by editors in files loaded into the environment.
+func TrimBOM(_, value string) (string, error) {
+	return strings.TrimPrefix(value, "\uFEFF"), nil
+}
+
+// URLDecode is a TransformFunc decoding percent-encoded values.
+func URLDecode(_, value string) (string, error) {
+	return url.PathUnescape(value)
+}
+
+var transforms atomic.Pointer[[]TransformFunc]
+
+// SetTransforms sets the TransformFunc applied in order to the values looked up by
+// the package-level functions. Values failing to transform are reported to the
+// OnParseError hook and used as is. Without arguments, it removes the transforms.
+// They are not applied to the values looked up through a Transform source.
+func SetTransforms(fns ...TransformFunc) {
+	if len(fns) == 0 {
+		transforms.Store(nil)
+		return
+	}
+	transforms.Store(&fns)
+}
+
+// transform applies the global transforms to the value of key.
+func transform(key, value string) string {
+	fns := transforms.Load()
+	if fns == nil {
+		return value
+	}
+	v, err := applyTransforms(*fns, key, value)
+	if err != nil {
+		parseError(key, value, err)
+		return value
+	}
+	return v
+}
+
+func applyTransforms(fns []TransformFunc, key, value string) (string, error) {
+	for _, fn := range fns {
+		var err error
+		if value, err = fn(key, value); err != nil {
+			return "", fmt.Errorf("transform: %w", err)
+		}
+	}
+	return value, nil
+}
+
+// skipTransformsKey marks the contexts of the lookups made by a Transform source,
+// for which OS does not apply the global transforms.
+type skipTransformsKey struct{}
+
+// Transform returns a Source applying fns in order to the values found in s.
+// The values read by OS through it are not transformed by the global transforms
+// set with SetTransforms: fns replace them, so that no transform runs twice.
+// Transform errors are returned as *ParseError.
+func Transform(s Source, fns ...TransformFunc) Source {
+	return SourceFunc(func(ctx context.Context, key string) (string, bool, error) {
+		v, ok, err := s.Lookup(context.WithValue(ctx, skipTransformsKey{}, true), key)
+		if err != nil || !ok {
+			return v, ok, err
+		}
+		t, err := applyTransforms(fns, key, v)
+		if err != nil {
+			return "", false, &ParseError{Key: key, Value: v, Err: err}
+		}
+		return t, true, nil
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSetTransforms(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_TRANSFORM_BOM":     "\uFEFF42",
+		"TEST_TRANSFORM_URL":     "a%2Cb%20c",
+		"TEST_TRANSFORM_INVALID": "%zz",
+		"TEST_TRANSFORM_LEGACY":  "yes please",
+	})
+	var failed []string
+	OnParseError(func(key, raw string, err error) {
+		failed = append(failed, key)
+	})
+	defer OnParseError(nil)
+	legacy := func(key, value string) (string, error) {
+		if key == "TEST_TRANSFORM_LEGACY" {
+			return strings.TrimSuffix(value, " please"), nil
+		}
+		return value, nil
+	}
+	SetTransforms(TrimBOM, URLDecode, legacy)
+	defer SetTransforms()
+	if v := Get[int]("TEST_TRANSFORM_BOM"); v != 42 {
+		t.Errorf("Get() = %v, want 42", v)
+	}
+	if v := Get[string]("TEST_TRANSFORM_URL"); v != "a,b c" {
+		t.Errorf("Get() = %q, want %q", v, "a,b c")
+	}
+	if v := Get[bool]("TEST_TRANSFORM_LEGACY"); !v {
+		t.Errorf("Get() = %v, want true", v)
+	}
+	if v := Get[string]("TEST_TRANSFORM_INVALID"); v != "%zz" || join(failed, ",") != "TEST_TRANSFORM_INVALID" {
+		t.Errorf("Get() = %q, parse errors %v, want raw value and reported error", v, failed)
+	}
+
+	SetTransforms()
+	if v := Get[string]("TEST_TRANSFORM_URL"); v != "a%2Cb%20c" {
+		t.Errorf("Get() = %q without transforms", v)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	src := Transform(SourceFunc(func(ctx context.Context, key string) (string, bool, error) {
+		return map[string]string{"A": "%41", "B": "%zz"}[key], key != "C", nil
+	}), URLDecode)
+	ctx := context.Background()
+	if v, err := GetCtx[string](ctx, "A", src); err != nil || v != "A" {
+		t.Errorf("GetCtx() = %q, %v, want A", v, err)
+	}
+	var perr *ParseError
+	if _, err := GetCtx[string](ctx, "B", src); !errors.As(err, &perr) {
+		t.Errorf("GetCtx() error = %v, want *ParseError", err)
+	}
+	if v, err := GetDefaultCtx(ctx, "C", "def", src); err != nil || v != "def" {
+		t.Errorf("GetDefaultCtx() = %q, %v, want def", v, err)
+	}
+}
+
+func TestTransformSkipsGlobal(t *testing.T) {
+	setAll(t, map[string]string{"TEST_TRANSFORM_TWICE": "%2541"})
+	SetTransforms(URLDecode)
+	defer SetTransforms()
+	ctx := context.Background()
+	if v, err := GetCtx[string](ctx, "TEST_TRANSFORM_TWICE"); err != nil || v != "%41" {
+		t.Errorf("GetCtx(OS) = %q, %v, want %%41", v, err)
+	}
+	if v, err := GetCtx[string](ctx, "TEST_TRANSFORM_TWICE", Transform(OS, URLDecode)); err != nil || v != "%41" {
+		t.Errorf("GetCtx(Transform(OS)) = %q, %v, want %%41 decoded once", v, err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator validates a populated struct.
+// *validator.Validate from github.com/go-playground/validator satisfies it.
+type Validator interface {
+	Struct(v any) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(v any) error
+
+func (fn ValidatorFunc) Struct(v any) error {
+	return fn(v)
+}
+
+// ValidationError is a validation failure of the field bound to the variable Key.
+type ValidationError struct {
+	Key   string
+	Field string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	if fe, ok := e.Err.(interface {
+		Tag() string
+		Param() string
+	}); ok {
+		if fe.Param() != "" {
+			return fmt.Sprintf("env: %s: validation failed on %q (%s)", e.Key, fe.Tag(), fe.Param())
+		}
+		return fmt.Sprintf("env: %s: validation failed on %q", e.Key, fe.Tag())
+	}
+	return fmt.Sprintf("env: %s: %v", e.Key, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError is implemented by the go-playground/validator field errors.
+type fieldError interface {
+	error
+	StructNamespace() string
+}
+
+// validationErrors maps the field errors reported by the validator to their variable names.
+// Errors that do not expose the field namespace are returned untouched.
+func validationErrors(err error, keys map[string]string) error {
+	rv := reflect.ValueOf(err)
+	if rv.Kind() != reflect.Slice {
+		if fe, ok := err.(fieldError); ok {
+			return Errors{validationError(fe, keys)}
+		}
+		return err
+	}
+	var errs Errors
+	for i := 0; i < rv.Len(); i++ {
+		fe, ok := rv.Index(i).Interface().(fieldError)
+		if !ok {
+			return err
+		}
+		errs = append(errs, validationError(fe, keys))
+	}
+	return errs
+}
+
+func validationError(fe fieldError, keys map[string]string) error {
+	// the namespace starts with the root struct type name, e.g. "Config.HTTP.Port"
+	ns := fe.StructNamespace()
+	_, path, _ := strings.Cut(ns, ".")
+	key, ok := keys[path]
+	if !ok {
+		key = ns
+	}
+	return &ValidationError{Key: key, Field: path, Err: fe}
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version returns a hash of the schema variables names, types, defaults and
+// required flags, which changes when the schema does.
+// It is meant to be deployed along the variables, e.g. as MYAPP_CONFIG_SCHEMA,
+// to detect configurations generated for another version, see VerifyVersion.
+func (s *Schema) Version() string {
+	lines := make([]string, len(s.Vars))
+	for i, v := range s.Vars {
+		lines[i] = fmt.Sprintf("%s %v %t %t %q %s %s", v.Name, v.Type, v.Required, v.HasDefault, v.Default, v.RequiredIf, v.DefaultFrom)
+	}
+	sort.Strings(lines)
+	h := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(h[:6])
+}
+
+// VersionError reports a configuration generated for another schema version.
+type VersionError struct {
+	// Key is the name of the variable holding the version.
+	Key  string
+	Want string
+	Got  string
+	// Missing lists the required variables without default that are not set.
+	Missing []string
+	// Unknown lists the set variables sharing the schema prefix that are not in the schema,
+	// e.g. removed ones.
+	Unknown []string
+}
+
+func (e *VersionError) Error() string {
+	got := e.Got
+	if got == "" {
+		got = "unset"
+	}
+	s := fmt.Sprintf("env: %s: configuration schema version %s, want %s", e.Key, got, e.Want)
+	if len(e.Missing) != 0 {
+		s += ", missing " + strings.Join(e.Missing, ", ")
+	}
+	if len(e.Unknown) != 0 {
+		s += ", unknown " + strings.Join(e.Unknown, ", ")
+	}
+	return s
+}
+
+// VerifyVersion compares the version held by the variable name with the schema Version,
+// returning a *VersionError listing the required variables that are missing and the
+// unknown ones if they differ. Unknown variables are the set variables starting with
+// prefix followed by an underscore, like MYAPP_, which are not in the schema.
+// No variable is reported as unknown when prefix is empty.
+// It is meant to warn about outdated configurations during rollouts rather than to fail.
+func (s *Schema) VerifyVersion(name, prefix string) error {
+	got, _ := lookup(name)
+	want := s.Version()
+	if got == want {
+		return nil
+	}
+	e := &VersionError{Key: Key(name), Want: want, Got: got}
+	known := map[string]bool{Key(name): true}
+	for _, v := range s.Vars {
+		known[Key(v.Name)] = true
+		if _, ok := lookup(v.Name); !ok && v.Required && !v.HasDefault {
+			e.Missing = append(e.Missing, v.Name)
+		}
+	}
+	if prefix != "" {
+		for _, k := range Keys(Key(prefix) + "_") {
+			if !known[k] {
+				e.Unknown = append(e.Unknown, k)
+			}
+		}
+	}
+	return e
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyVersion(t *testing.T) {
+	type v1 struct {
+		Addr string `env:"TEST_VERSION_ADDR" default:":8080"`
+		Old  string `env:"TEST_VERSION_OLD"`
+	}
+	type v2 struct {
+		Addr  string `env:"TEST_VERSION_ADDR" default:":8080"`
+		Token string `env:"TEST_VERSION_TOKEN,required"`
+	}
+	s1, err := NewSchema(v1{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := NewSchema(v2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1.Version() == s2.Version() || len(s1.Version()) != 12 {
+		t.Fatalf("Version() = %s and %s, want distinct hashes", s1.Version(), s2.Version())
+	}
+	if again, _ := NewSchema(&v1{}); again.Version() != s1.Version() {
+		t.Error("Version() is not stable")
+	}
+	setAll(t, map[string]string{
+		"TEST_VERSION_SCHEMA": s1.Version(),
+		"TEST_VERSION_OLD":    "x",
+	})
+	if err := s1.VerifyVersion("TEST_VERSION_SCHEMA", "TEST_VERSION"); err != nil {
+		t.Errorf("VerifyVersion() = %v", err)
+	}
+	err = s2.VerifyVersion("TEST_VERSION_SCHEMA", "TEST_VERSION")
+	var verr *VersionError
+	if !errors.As(err, &verr) {
+		t.Fatalf("VerifyVersion() error = %v, want *VersionError", err)
+	}
+	if verr.Got != s1.Version() || verr.Want != s2.Version() || join(verr.Missing, ",") != "TEST_VERSION_TOKEN" || join(verr.Unknown, ",") != "TEST_VERSION_OLD" {
+		t.Errorf("VerifyVersion() = %+v", verr)
+	}
+	want := "env: TEST_VERSION_SCHEMA: configuration schema version " + s1.Version() + ", want " + s2.Version() +
+		", missing TEST_VERSION_TOKEN, unknown TEST_VERSION_OLD"
+	if err.Error() != want {
+		t.Errorf("Error() = %s, want %s", err, want)
+	}
+}
+
+func TestVerifyVersionSingleVar(t *testing.T) {
+	s, err := NewSchema(struct {
+		Token string `env:"TEST_VERSION_ONE_TOKEN,required"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setAll(t, map[string]string{"TEST_VERSION_ONE_OLD": "x"})
+	var verr *VersionError
+	if err := s.VerifyVersion("TEST_VERSION_ONE_SCHEMA", ""); !errors.As(err, &verr) || len(verr.Unknown) != 0 {
+		t.Errorf("VerifyVersion() = %v, want no unknown variable without prefix", err)
+	}
+	if err := s.VerifyVersion("TEST_VERSION_ONE_SCHEMA", "TEST_VERSION_ONE"); !errors.As(err, &verr) || join(verr.Unknown, ",") != "TEST_VERSION_ONE_OLD" {
+		t.Errorf("VerifyVersion() = %v, want TEST_VERSION_ONE_OLD unknown", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Weighted is a value associated with a positive weight, e.g. a load balancer upstream.
+type Weighted[T Value] struct {
+	Value  T
+	Weight uint
+}
+
+func (w Weighted[T]) String() string {
+	return fmt.Sprintf("%v=%d", w.Value, w.Weight)
+}
+
+// GetWeighted parses the comma separated list of weighted values of the variable name,
+// e.g. UPSTREAMS=a:10,b:1, returning def if the variable is not set or empty.
+// The weight is separated from the value by the last "=" or, if the element has none,
+// by the last ":". Values containing a ":", like host:port addresses, must therefore
+// use "=", e.g. 10.0.0.1:8080=10. Elements without weight default to 1.
+func GetWeighted[T Value](name string, def []Weighted[T]) ([]Weighted[T], error) {
+	key := Key(name)
+	raw, ok := lookupOS(key)
+	if !ok {
+		missing(key)
+		return def, nil
+	}
+	elems := splitSlice(raw)
+	if len(elems) == 0 {
+		return def, nil
+	}
+	out := make([]Weighted[T], 0, len(elems))
+	for i, e := range elems {
+		w, err := parseWeighted[T](e)
+		if err != nil {
+			return nil, &ParseError{Key: fmt.Sprintf("%s[%d]", key, i), Value: e, Err: err}
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+func parseWeighted[T Value](s string) (Weighted[T], error) {
+	w := Weighted[T]{Weight: 1}
+	v := s
+	i := strings.LastIndexByte(s, '=')
+	if i < 0 {
+		i = strings.LastIndexByte(s, ':')
+	}
+	if i >= 0 {
+		v = s[:i]
+		n, err := strconv.ParseUint(strings.TrimSpace(s[i+1:]), 10, 0)
+		if err != nil {
+			return w, fmt.Errorf("invalid weight: %w", err)
+		}
+		if n == 0 {
+			return w, errors.New("weight must be positive")
+		}
+		w.Weight = uint(n)
+	}
+	if err := parseValue(v, &w.Value); err != nil {
+		return w, err
+	}
+	return w, nil
+}
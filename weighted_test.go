@@ -0,0 +1,46 @@
+// Copyright 2023 Linka Cloud  All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestGetWeighted(t *testing.T) {
+	setAll(t, map[string]string{
+		"TEST_UPSTREAMS": "a:10, b:1,c",
+		"TEST_ADDRS":     "10.0.0.1:8080=3,10.0.0.2:8080=1",
+		"TEST_ZERO":      "a:0",
+		"TEST_BAD":       "a:x",
+	})
+	got, err := GetWeighted[string]("TEST_UPSTREAMS", nil)
+	if err != nil || join(got, ",") != "a=10,b=1,c=1" {
+		t.Errorf("GetWeighted() = %v, %v", got, err)
+	}
+	addrs, err := GetWeighted[netip.AddrPort]("TEST_ADDRS", nil)
+	if err != nil || join(addrs, ",") != "10.0.0.1:8080=3,10.0.0.2:8080=1" {
+		t.Errorf("GetWeighted() = %v, %v", addrs, err)
+	}
+	for _, k := range []string{"TEST_ZERO", "TEST_BAD"} {
+		if _, err := GetWeighted[string](k, nil); err == nil {
+			t.Errorf("GetWeighted(%s) expected error", k)
+		}
+	}
+	def := []Weighted[string]{{Value: "d", Weight: 1}}
+	if got, err := GetWeighted("TEST_MISSING", def); err != nil || join(got, ",") != "d=1" {
+		t.Errorf("GetWeighted() = %v, %v, want default", got, err)
+	}
+}